@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// dsnEnv holds a connection string when -db-url is empty; child processes of -schemas get theirs from it,
+// so secrets never show up in their command lines
+const dsnEnv = "TINY_CDS_LOADER_DSN"
+
+// ConnectionConfig describes how to reach the database. Anything left empty falls back to the DSN,
+// then to the PG* environment variables and ~/.pgpass, which lib/pq reads itself.
+type ConnectionConfig struct {
+	DSN         string // postgres:// URL or key=value connection string
+	User        string
+	Password    string
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+}
+
+// sslModes are the modes lib/pq supports
+var sslModes = map[string]bool{"disable": true, "require": true, "verify-ca": true, "verify-full": true}
+
+// connString builds a key=value connection string: the DSN first, then the flags, which win as later
+// keys override earlier ones. Without any sslmode the loader keeps its historical sslmode=disable.
+func (c ConnectionConfig) connString() (string, error) {
+	dsn := strings.TrimSpace(c.DSN)
+	if dsn == "" {
+		dsn = os.Getenv(dsnEnv)
+	}
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		converted, err := pq.ParseURL(dsn)
+		if err != nil {
+			// The parse error quotes the URL, password included
+			return "", fmt.Errorf("invalid database URL %s", redactSecrets(dsn))
+		}
+		dsn = converted
+	}
+
+	parts := []string{}
+	if dsn != "" {
+		parts = append(parts, dsn)
+	}
+	for _, o := range []struct{ key, value string }{
+		{"user", c.User},
+		{"password", c.Password},
+		{"sslmode", c.SSLMode},
+		{"sslrootcert", c.SSLRootCert},
+		{"sslcert", c.SSLCert},
+		{"sslkey", c.SSLKey},
+	} {
+		if o.value != "" {
+			parts = append(parts, o.key+"="+quoteConnValue(o.value))
+		}
+	}
+
+	if c.SSLMode != "" && !sslModes[c.SSLMode] {
+		return "", fmt.Errorf("invalid sslmode %q, expected disable, require, verify-ca or verify-full", c.SSLMode)
+	}
+	if c.SSLMode == "" && !connStringKeys(dsn)["sslmode"] && os.Getenv("PGSSLMODE") == "" {
+		parts = append(parts, "sslmode=disable")
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// quoteConnValue quotes a key=value connection string value, escaping backslashes and quotes
+func quoteConnValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+// connStringKeys returns the keys a key=value connection string sets. Quoted values are skipped as a
+// whole, so a key=value inside a password is not mistaken for a key. Parsing stops at the first malformed
+// pair, lib/pq reports it when connecting.
+func connStringKeys(connStr string) map[string]bool {
+	keys := make(map[string]bool)
+	s := connStr
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return keys
+		}
+		key := strings.TrimRightFunc(s[:eq], unicode.IsSpace)
+		if strings.IndexFunc(key, unicode.IsSpace) >= 0 {
+			return keys
+		}
+		keys[key] = true
+
+		s = strings.TrimLeftFunc(s[eq+1:], unicode.IsSpace)
+		if !strings.HasPrefix(s, "'") {
+			end := strings.IndexFunc(s, unicode.IsSpace)
+			if end < 0 {
+				return keys
+			}
+			s = s[end:]
+			continue
+		}
+
+		i := 1
+		for ; i < len(s) && s[i] != '\''; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(s) {
+			return keys
+		}
+		s = s[i+1:]
+	}
+}
+
+// secretPatterns match passwords in key=value connection strings, in URLs and in -password flags
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|[^\s&]*)`),
+	regexp.MustCompile(`(://[^:/@\s]*:)([^@\s]*)(@)`),
+}
+
+// redactSecrets hides the passwords of a connection string, URL or error message before it is printed
+func redactSecrets(s string) string {
+	s = secretPatterns[0].ReplaceAllString(s, "${1}xxxxx")
+	return secretPatterns[1].ReplaceAllString(s, "${1}xxxxx${3}")
+}
+
+// searchPathParam returns the connection parameter selecting schema on every new connection.
+// lib/pq sends unknown parameters as run-time settings in the startup message, so each pooled
+// connection starts with the search_path instead of relying on a SET on a single one. public follows
+// the schema, like in CreateSchema, as it holds the functions and operators of the ltree extension.
+func searchPathParam(schema string) string {
+	return "search_path=" + quoteConnValue(pq.QuoteIdentifier(schema)+", public")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConnectionConfigConnString(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ConnectionConfig
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"empty", ConnectionConfig{}, nil, "sslmode=disable", false},
+		{"key=value DSN", ConnectionConfig{DSN: "host=db dbname=cds"}, nil, "host=db dbname=cds sslmode=disable", false},
+		{"DSN from the environment", ConnectionConfig{}, map[string]string{dsnEnv: "host=db"}, "host=db sslmode=disable", false},
+		{"flag DSN wins over the environment", ConnectionConfig{DSN: "host=flag"}, map[string]string{dsnEnv: "host=env"}, "host=flag sslmode=disable", false},
+		{"URL", ConnectionConfig{DSN: "postgres://u@db:5433/cds"}, nil, "dbname='cds' host='db' port='5433' user='u' sslmode=disable", false},
+		{"flags override the DSN", ConnectionConfig{DSN: "host=db user=a", User: "b", Password: "it's"}, nil, `host=db user=a user='b' password='it\'s' sslmode=disable`, false},
+		{"sslmode flag", ConnectionConfig{SSLMode: "verify-full", SSLRootCert: "/ca.pem"}, nil, "sslmode='verify-full' sslrootcert='/ca.pem'", false},
+		{"client certificate", ConnectionConfig{SSLMode: "require", SSLCert: "/c.pem", SSLKey: "/k.pem"}, nil, "sslmode='require' sslcert='/c.pem' sslkey='/k.pem'", false},
+		{"sslmode in the DSN", ConnectionConfig{DSN: "host=db sslmode=require"}, nil, "host=db sslmode=require", false},
+		{"sslmode in the URL", ConnectionConfig{DSN: "postgresql://db/cds?sslmode=require"}, nil, "dbname='cds' host='db' sslmode='require'", false},
+		{"sslmode inside a quoted password", ConnectionConfig{DSN: "host=db password='x sslmode=y'"}, nil, "host=db password='x sslmode=y' sslmode=disable", false},
+		{"PGSSLMODE", ConnectionConfig{DSN: "host=db"}, map[string]string{"PGSSLMODE": "require"}, "host=db", false},
+		{"invalid sslmode", ConnectionConfig{SSLMode: "prefer"}, nil, "", true},
+		{"invalid URL", ConnectionConfig{DSN: "postgres://u:secret@db:port/cds"}, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(dsnEnv, "")
+			t.Setenv("PGSSLMODE", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			got, err := tt.cfg.connString()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret") {
+				t.Errorf("error %q leaks the password", err)
+			}
+			if got != tt.want {
+				t.Errorf("connString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnStringKeys(t *testing.T) {
+	tests := []struct {
+		connStr string
+		want    []string
+	}{
+		{"", nil},
+		{"host=db sslmode=require", []string{"host", "sslmode"}},
+		{"  host = db\tport=5432 ", []string{"host", "port"}},
+		{"host=db password='x sslmode=y'", []string{"host", "password"}},
+		{`password='it\'s sslmode=y' user=u`, []string{"password", "user"}},
+		{"password='unterminated sslmode=y", []string{"password"}},
+		{"host=db garbage sslmode=require", []string{"host"}},
+		{"sslmode= host=db", []string{"sslmode"}},
+	}
+	for _, tt := range tests {
+		got := connStringKeys(tt.connStr)
+		if len(got) != len(tt.want) {
+			t.Errorf("connStringKeys(%q) = %v, want %v", tt.connStr, got, tt.want)
+			continue
+		}
+		for _, key := range tt.want {
+			if !got[key] {
+				t.Errorf("connStringKeys(%q) = %v, want %v", tt.connStr, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestQuoteConnValue(t *testing.T) {
+	tests := []struct {
+		v    string
+		want string
+	}{
+		{"", "''"},
+		{"secret", "'secret'"},
+		{"with space", "'with space'"},
+		{"it's", `'it\'s'`},
+		{`back\slash`, `'back\\slash'`},
+		{`"tenant_1", public`, `'"tenant_1", public'`},
+	}
+	for _, tt := range tests {
+		if got := quoteConnValue(tt.v); got != tt.want {
+			t.Errorf("quoteConnValue(%q) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"host=db password=secret user=u", "host=db password=xxxxx user=u"},
+		{"host=db password = 'se cr\\'et' user=u", "host=db password = xxxxx user=u"},
+		{"postgres://u:secret@db:5432/cds", "postgres://u:xxxxx@db:5432/cds"},
+		{"postgres://u@db/cds?password=secret&sslmode=require", "postgres://u@db/cds?password=xxxxx&sslmode=require"},
+		{`pq: invalid URL "postgres://u:secret@db:port/cds"`, `pq: invalid URL "postgres://u:xxxxx@db:port/cds"`},
+		{"host=db user=u", "host=db user=u"},
+	}
+	for _, tt := range tests {
+		if got := redactSecrets(tt.s); got != tt.want {
+			t.Errorf("redactSecrets(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSearchPathParam(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{"public", `search_path='"public", public'`},
+		{"tenant_001", `search_path='"tenant_001", public'`},
+		{"Odd's", `search_path='"Odd\'s", public'`},
+	}
+	for _, tt := range tests {
+		if got := searchPathParam(tt.schema); got != tt.want {
+			t.Errorf("searchPathParam(%q) = %s, want %s", tt.schema, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	DefaultAuthorCount       = 10000
+	DefaultAuthorAlpha       = 1.16 // Pareto shape giving roughly an 80/20 split of products between authors
+	DefaultAuthorSeed        = 1
+	DefaultCrossCategoryRate = 0.05
+	secondCategoryRate       = 0.3 // Share of authors publishing in a second top-level category
+)
+
+// AuthorConfig controls the author population products are attributed to
+type AuthorConfig struct {
+	Count             int     // Number of distinct authors
+	Alpha             float64 // Pareto shape of the products-per-author distribution (lower = more skewed)
+	Seed              int64   // Seed of the author model, keep it fixed so appended products reuse the same authors
+	CrossCategoryRate float64 // Share of products published outside the author's own categories
+}
+
+// authorPool picks authors in proportion to their activity weight
+type authorPool struct {
+	ids        []int64
+	cumulative []float64
+}
+
+func (p *authorPool) add(id int64, weight float64) {
+	sum := weight
+	if n := len(p.cumulative); n > 0 {
+		sum += p.cumulative[n-1]
+	}
+	p.ids = append(p.ids, id)
+	p.cumulative = append(p.cumulative, sum)
+}
+
+func (p *authorPool) pick(rng *rand.Rand) int64 {
+	r := rng.Float64() * p.cumulative[len(p.cumulative)-1]
+	return p.ids[sort.SearchFloat64s(p.cumulative, r)]
+}
+
+// authorModel assigns authors to products: every author has a Pareto distributed activity
+// weight and publishes mostly in one or two top-level categories
+type authorModel struct {
+	cfg        AuthorConfig
+	all        *authorPool
+	byCategory map[int64]*authorPool
+}
+
+func (cfg AuthorConfig) validate() error {
+	if cfg.Count < 1 {
+		return fmt.Errorf("author count must be >= 1, got %d", cfg.Count)
+	}
+	if cfg.Alpha <= 0 {
+		return fmt.Errorf("author alpha must be > 0, got %.2f", cfg.Alpha)
+	}
+	if cfg.CrossCategoryRate < 0 || cfg.CrossCategoryRate > 1 {
+		return fmt.Errorf("cross-category rate must be between 0 and 1, got %.2f", cfg.CrossCategoryRate)
+	}
+	return nil
+}
+
+// buildAuthorModel generates the author population deterministically from cfg.Seed
+func buildAuthorModel(cfg AuthorConfig) *authorModel {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	categoryWeights := buildCategoryWeights()
+
+	m := &authorModel{
+		cfg:        cfg,
+		all:        &authorPool{},
+		byCategory: make(map[int64]*authorPool),
+	}
+	for _, cat := range categories {
+		m.byCategory[cat.ID] = &authorPool{}
+	}
+
+	for id := int64(1); id <= int64(cfg.Count); id++ {
+		weight := 1 / math.Pow(1-rng.Float64(), 1/cfg.Alpha)
+		m.all.add(id, weight)
+
+		primary := selectCategoryByWeight(rng, categoryWeights)
+		m.byCategory[primary].add(id, weight)
+		if rng.Float64() < secondCategoryRate {
+			if secondary := selectCategoryByWeight(rng, categoryWeights); secondary != primary {
+				m.byCategory[secondary].add(id, weight)
+			}
+		}
+	}
+
+	// Small categories may not have drawn any author, give them one so every category can be published in
+	for _, cat := range categories {
+		if pool := m.byCategory[cat.ID]; len(pool.ids) == 0 {
+			id := int64(rng.Intn(cfg.Count) + 1)
+			pool.add(id, 1)
+		}
+	}
+	return m
+}
+
+// pick returns the author of a product published in categoryID
+func (m *authorModel) pick(rng *rand.Rand, categoryID int64) int64 {
+	pool, ok := m.byCategory[categoryID]
+	if !ok || rng.Float64() < m.cfg.CrossCategoryRate {
+		pool = m.all
+	}
+	return pool.pick(rng)
+}
+
+var authorHistogramBuckets = []struct {
+	label string
+	max   int64
+}{
+	{"1", 1},
+	{"2-5", 5},
+	{"6-20", 20},
+	{"21-100", 100},
+	{"101-1k", 1000},
+	{"1k-10k", 10000},
+	{"10k+", math.MaxInt64},
+}
+
+// verifyAuthors prints the products-per-author histogram and how many categories authors publish in
+func verifyAuthors(db *sql.DB, _ *verifyReport) error {
+	rows, err := db.Query("SELECT author_id, COUNT(*), COUNT(DISTINCT category_id) FROM product GROUP BY author_id")
+	if err != nil {
+		return fmt.Errorf("failed to count products per author: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := make(map[string]int64)
+	categoryCounts := make(map[int64]int64)
+	var authors, products, topCount, topAuthor int64
+	for rows.Next() {
+		var authorID, count, distinctCategories int64
+		if err := rows.Scan(&authorID, &count, &distinctCategories); err != nil {
+			return fmt.Errorf("failed to scan author count: %w", err)
+		}
+		authors++
+		products += count
+		if count > topCount {
+			topCount, topAuthor = count, authorID
+		}
+		if distinctCategories > 3 {
+			distinctCategories = 4
+		}
+		categoryCounts[distinctCategories]++
+		for _, b := range authorHistogramBuckets {
+			if count <= b.max {
+				buckets[b.label]++
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if authors == 0 {
+		fmt.Println("  No products found")
+		return nil
+	}
+
+	fmt.Printf("  %d authors, %.1f products per author on average, top author %d has %d products (%.2f%%)\n",
+		authors, float64(products)/float64(authors), topAuthor, topCount, 100*float64(topCount)/float64(products))
+	fmt.Println("  Products per author:")
+	for _, b := range authorHistogramBuckets {
+		fmt.Printf("    %-7s %d authors\n", b.label, buckets[b.label])
+	}
+	fmt.Println("  Top-level categories per author:")
+	for n := int64(1); n <= 4; n++ {
+		label := fmt.Sprintf("%d", n)
+		if n == 4 {
+			label = "4+"
+		}
+		fmt.Printf("    %-7s %d authors\n", label, categoryCounts[n])
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package loader
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAuthorConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     AuthorConfig
+		wantErr bool
+	}{
+		{AuthorConfig{Count: DefaultAuthorCount, Alpha: DefaultAuthorAlpha, CrossCategoryRate: DefaultCrossCategoryRate}, false},
+		{AuthorConfig{Count: 1, Alpha: 0.5, CrossCategoryRate: 1}, false},
+		{AuthorConfig{Count: 0, Alpha: 1}, true},
+		{AuthorConfig{Count: 10, Alpha: 0}, true},
+		{AuthorConfig{Count: 10, Alpha: 1, CrossCategoryRate: 1.5}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: got error %v, want error %t", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAuthorModel(t *testing.T) {
+	cfg := AuthorConfig{Count: 2000, Alpha: DefaultAuthorAlpha, Seed: DefaultAuthorSeed, CrossCategoryRate: 0}
+
+	// The same seed always gives the same population, so appended products reuse the authors
+	m := buildAuthorModel(cfg)
+	if !reflect.DeepEqual(m, buildAuthorModel(cfg)) {
+		t.Fatal("two models built from the same seed differ")
+	}
+	for _, cat := range categories {
+		if len(m.byCategory[cat.ID].ids) == 0 {
+			t.Errorf("category %d has no author", cat.ID)
+		}
+	}
+
+	// Without cross-category products every author comes from the category's pool
+	rng := rand.New(rand.NewSource(1))
+	products := make(map[int64]int)
+	const n = 50000
+	for i := 0; i < n; i++ {
+		id := m.pick(rng, 553)
+		if id < 1 || id > int64(cfg.Count) {
+			t.Fatalf("author %d outside 1..%d", id, cfg.Count)
+		}
+		products[id]++
+	}
+	inCategory := make(map[int64]bool)
+	for _, id := range m.byCategory[553].ids {
+		inCategory[id] = true
+	}
+	counts := make([]int, 0, len(products))
+	for id, count := range products {
+		if !inCategory[id] {
+			t.Fatalf("author %d doesn't publish in category 553", id)
+		}
+		counts = append(counts, count)
+	}
+
+	// Heavy tail: the top fifth of the authors hold well over half of the products
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	top := 0
+	for _, count := range counts[:len(counts)/5] {
+		top += count
+	}
+	if share := float64(top) / n; share < 0.6 {
+		t.Errorf("top 20%% of the authors hold %.2f of the products", share)
+	}
+}
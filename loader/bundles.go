@@ -0,0 +1,334 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+const (
+	bundlesCategoryID      int64 = 546   // Category whose products are bundles
+	bundleBatchSize              = 500   // Bundles per insert batch
+	bundleCategoryPoolSize       = 50000 // Candidate products kept per category for filling bundles
+	bundleAuthorPoolFactor       = 4     // Candidate products kept per author, as a multiple of the max bundle size
+	DefaultBundleMinSize         = 3
+	DefaultBundleMaxSize         = 12
+)
+
+// BundleConfig controls how many member products each bundle gets
+type BundleConfig struct {
+	MinSize int
+	MaxSize int
+}
+
+// bundleCandidates holds reservoir samples of non-bundle products per author and per category
+type bundleCandidates struct {
+	byAuthor        map[int64][]int64
+	authorSeen      map[int64]int
+	authorCategory  map[int64]map[int64]bool
+	byCategory      map[int64][]int64
+	categorySeen    map[int64]int
+	categoryIDs     []int64
+	categoryWeights []float64
+}
+
+func (l *Loader) ImportBundles(cfg BundleConfig) error {
+	fmt.Print("\n=== Importing Bundle Products ===\n\n")
+
+	if cfg.MinSize < 1 || cfg.MaxSize < cfg.MinSize {
+		return fmt.Errorf("invalid bundle size range %d-%d", cfg.MinSize, cfg.MaxSize)
+	}
+
+	// Only bundles without members yet, so the mode is safe to re-run
+	rows, err := l.db.Query(`
+		SELECT p.product_id, p.author_id
+		FROM product p
+		WHERE p.category_id = $1
+		  AND NOT EXISTS (SELECT 1 FROM bundle_products bp WHERE bp.product_bundle_id = p.product_id)
+		ORDER BY p.product_id
+	`, bundlesCategoryID)
+	if err != nil {
+		return fmt.Errorf("failed to load bundle products: %w", err)
+	}
+
+	type bundle struct {
+		productID int64
+		authorID  int64
+	}
+	var bundles []bundle
+	bundleAuthors := make(map[int64]bool)
+	for rows.Next() {
+		var b bundle
+		if err := rows.Scan(&b.productID, &b.authorID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan bundle product: %w", err)
+		}
+		bundles = append(bundles, b)
+		bundleAuthors[b.authorID] = true
+	}
+	rows.Close()
+
+	if len(bundles) == 0 {
+		fmt.Println("No bundle products without members found - nothing to do")
+		return nil
+	}
+
+	fmt.Printf("Found %d bundle products without members\n", len(bundles))
+	fmt.Println("Sampling candidate member products...")
+
+	candidates, err := loadBundleCandidates(l.db, l.newRand(-1), bundleAuthors, cfg.MaxSize*bundleAuthorPoolFactor)
+	if err != nil {
+		return err
+	}
+	if len(candidates.categoryIDs) == 0 {
+		return fmt.Errorf("no non-bundle products found - please import products first")
+	}
+
+	fmt.Printf("Building bundles of %d-%d products using %d workers...\n", cfg.MinSize, cfg.MaxSize, Workers)
+
+	bar := l.newProgressBar(len(bundles), "Bundles")
+
+	jobs := make(chan []bundle, 100)
+	errors := make(chan error, 1000)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sizeCounts := make(map[int]int)
+	var totalInserted int64
+	totalMembers := 0
+	sameAuthorMembers := 0
+	var firstError error
+
+	// Error collector goroutine
+	var errorWg sync.WaitGroup
+	errorWg.Add(1)
+	go func() {
+		defer errorWg.Done()
+		for err := range errors {
+			if err != nil && firstError == nil {
+				mu.Lock()
+				if firstError == nil {
+					firstError = err
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	// Start worker goroutines
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			rng := l.newRand(int64(workerID) * 1000)
+
+			for batch := range jobs {
+				var bundleIDs, memberIDs []int64
+				batchSizes := make([]int, 0, len(batch))
+				batchSameAuthor := 0
+
+				for _, b := range batch {
+					size := cfg.MinSize + rng.Intn(cfg.MaxSize-cfg.MinSize+1)
+					members, sameAuthor := candidates.pick(rng, b.productID, b.authorID, size)
+					for _, memberID := range members {
+						bundleIDs = append(bundleIDs, b.productID)
+						memberIDs = append(memberIDs, memberID)
+					}
+					batchSizes = append(batchSizes, len(members))
+					batchSameAuthor += sameAuthor
+				}
+
+				inserted, err := insertBundleBatch(l.db, bundleIDs, memberIDs)
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: %w", workerID, err)
+					continue
+				}
+
+				mu.Lock()
+				for _, size := range batchSizes {
+					sizeCounts[size]++
+				}
+				totalInserted += inserted
+				totalMembers += len(memberIDs)
+				sameAuthorMembers += batchSameAuthor
+				bar.Add(len(batch))
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	// Send jobs to workers
+	go func() {
+		for start := 0; start < len(bundles); start += bundleBatchSize {
+			end := start + bundleBatchSize
+			if end > len(bundles) {
+				end = len(bundles)
+			}
+			jobs <- bundles[start:end]
+		}
+		close(jobs)
+	}()
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(errors)
+
+	// Wait for error collector to finish
+	errorWg.Wait()
+
+	// Check if there was an error
+	if firstError != nil {
+		return firstError
+	}
+
+	// Members that already existed are skipped by ON CONFLICT, the sizes and shares are the generated ones
+	fmt.Printf("\n  ✓ Inserted: %d bundle members (%d generated for %d bundles, avg size %.2f, %.1f%% from the same author)\n",
+		totalInserted, totalMembers, len(bundles), float64(totalMembers)/float64(len(bundles)),
+		100*float64(sameAuthorMembers)/float64(max(totalMembers, 1)))
+
+	sizes := make([]int, 0, len(sizeCounts))
+	for size := range sizeCounts {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	fmt.Println("  Bundle size distribution:")
+	for _, size := range sizes {
+		fmt.Printf("    %3d products: %d bundles (%.1f%%)\n", size, sizeCounts[size], 100*float64(sizeCounts[size])/float64(len(bundles)))
+	}
+	fmt.Println()
+	return nil
+}
+
+// loadBundleCandidates streams every non-bundle product once and keeps reservoir samples
+// of the products of each bundle author and of each category
+func loadBundleCandidates(db *sql.DB, rng *rand.Rand, bundleAuthors map[int64]bool, perAuthor int) (*bundleCandidates, error) {
+	rows, err := db.Query("SELECT product_id, author_id, category_id FROM product WHERE category_id <> $1", bundlesCategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load candidate products: %w", err)
+	}
+	defer rows.Close()
+
+	c := &bundleCandidates{
+		byAuthor:       make(map[int64][]int64),
+		authorSeen:     make(map[int64]int),
+		authorCategory: make(map[int64]map[int64]bool),
+		byCategory:     make(map[int64][]int64),
+		categorySeen:   make(map[int64]int),
+	}
+
+	scanned := 0
+	for rows.Next() {
+		var productID, authorID, categoryID int64
+		if err := rows.Scan(&productID, &authorID, &categoryID); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate product: %w", err)
+		}
+		scanned++
+
+		if bundleAuthors[authorID] {
+			if c.authorCategory[authorID] == nil {
+				c.authorCategory[authorID] = make(map[int64]bool)
+			}
+			c.authorCategory[authorID][categoryID] = true
+
+			c.authorSeen[authorID]++
+			if pool := c.byAuthor[authorID]; len(pool) < perAuthor {
+				c.byAuthor[authorID] = append(pool, productID)
+			} else if j := rng.Intn(c.authorSeen[authorID]); j < perAuthor {
+				pool[j] = productID
+			}
+		}
+
+		c.categorySeen[categoryID]++
+		if pool := c.byCategory[categoryID]; len(pool) < bundleCategoryPoolSize {
+			c.byCategory[categoryID] = append(pool, productID)
+		} else if j := rng.Intn(c.categorySeen[categoryID]); j < bundleCategoryPoolSize {
+			pool[j] = productID
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load candidate products: %w", err)
+	}
+
+	// Global fallback picks a category in proportion to its real product count. Categories are
+	// visited in ID order so a seeded run builds the same bundles.
+	for categoryID := range c.categorySeen {
+		c.categoryIDs = append(c.categoryIDs, categoryID)
+	}
+	sort.Slice(c.categoryIDs, func(i, j int) bool { return c.categoryIDs[i] < c.categoryIDs[j] })
+	sum := 0.0
+	for _, categoryID := range c.categoryIDs {
+		sum += float64(c.categorySeen[categoryID])
+		c.categoryWeights = append(c.categoryWeights, sum)
+	}
+
+	fmt.Printf("Scanned %d candidate products from %d categories\n", scanned, len(c.categoryIDs))
+	return c, nil
+}
+
+// pick selects up to size distinct members for a bundle: products of the same author first,
+// then products from the categories that author publishes in, then from the whole catalog
+func (c *bundleCandidates) pick(rng *rand.Rand, bundleID, authorID int64, size int) ([]int64, int) {
+	members := make([]int64, 0, size)
+	seen := map[int64]bool{bundleID: true}
+
+	own := c.byAuthor[authorID]
+	for _, i := range rng.Perm(len(own)) {
+		if len(members) == size {
+			break
+		}
+		if !seen[own[i]] {
+			seen[own[i]] = true
+			members = append(members, own[i])
+		}
+	}
+	sameAuthor := len(members)
+
+	var related []int64
+	for categoryID := range c.authorCategory[authorID] {
+		related = append(related, categoryID)
+	}
+	sort.Slice(related, func(i, j int) bool { return related[i] < related[j] })
+
+	// Bounded retries keep tiny catalogs from looping forever
+	for attempts := 0; len(members) < size && attempts < size*20; attempts++ {
+		var pool []int64
+		if len(related) > 0 && rng.Float64() < 0.8 {
+			pool = c.byCategory[related[rng.Intn(len(related))]]
+		} else {
+			r := rng.Float64() * c.categoryWeights[len(c.categoryWeights)-1]
+			pool = c.byCategory[c.categoryIDs[sort.SearchFloat64s(c.categoryWeights, r)]]
+		}
+		if len(pool) == 0 {
+			continue
+		}
+		productID := pool[rng.Intn(len(pool))]
+		if !seen[productID] {
+			seen[productID] = true
+			members = append(members, productID)
+		}
+	}
+
+	return members, sameAuthor
+}
+
+// insertBundleBatch inserts the bundle members and returns how many were new
+func insertBundleBatch(db *sql.DB, bundleIDs, memberIDs []int64) (int64, error) {
+	if len(bundleIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO bundle_products (product_bundle_id, product_id)
+		SELECT * FROM unnest($1::bigint[], $2::bigint[])
+		ON CONFLICT DO NOTHING
+	`, pq.Array(bundleIDs), pq.Array(memberIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert bundle products: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,57 @@
+package loader
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBundleCandidatesPick(t *testing.T) {
+	c := &bundleCandidates{
+		byAuthor:        map[int64][]int64{7: {101, 102, 103}},
+		authorCategory:  map[int64]map[int64]bool{7: {23: true}},
+		byCategory:      map[int64][]int64{23: {101, 201, 202, 203, 204}, 553: {301, 302, 303, 304, 305, 306}},
+		categoryIDs:     []int64{23, 553},
+		categoryWeights: []float64{5, 11},
+	}
+
+	tests := []struct {
+		name       string
+		authorID   int64
+		size       int
+		sameAuthor int
+	}{
+		{"author products only", 7, 2, 2},
+		{"author then categories", 7, 8, 3},
+		{"author without products", 9, 5, 0},
+	}
+	for _, tt := range tests {
+		members, sameAuthor := c.pick(rand.New(rand.NewSource(1)), 100, tt.authorID, tt.size)
+		if len(members) != tt.size || sameAuthor != tt.sameAuthor {
+			t.Errorf("%s: got %d members, %d of the same author, want %d and %d", tt.name, len(members), sameAuthor, tt.size, tt.sameAuthor)
+		}
+		seen := map[int64]bool{}
+		for i, id := range members {
+			if id == 100 || seen[id] {
+				t.Errorf("%s: member %d is the bundle itself or a duplicate", tt.name, id)
+			}
+			seen[id] = true
+			if own := id >= 101 && id <= 103; i < sameAuthor && !own {
+				t.Errorf("%s: member %d at position %d is not a product of the author", tt.name, id, i)
+			}
+		}
+	}
+}
+
+func TestBundleCandidatesPickSmallCatalog(t *testing.T) {
+	c := &bundleCandidates{
+		byCategory:      map[int64][]int64{23: {1, 2}},
+		categoryIDs:     []int64{23},
+		categoryWeights: []float64{2},
+	}
+
+	// Bounded retries stop once the catalog runs out of products
+	members, _ := c.pick(rand.New(rand.NewSource(1)), 1, 9, 5)
+	if len(members) != 1 || members[0] != 2 {
+		t.Errorf("got members %v, want [2]", members)
+	}
+}
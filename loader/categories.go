@@ -0,0 +1,374 @@
+package loader
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"tiny-cds-loader/writer"
+)
+
+// Category represents a product category
+type Category struct {
+	ID         int64
+	Slug       string
+	Percentage float64
+	Price      PriceModel
+}
+
+// Subcategory represents a product subcategory
+type Subcategory struct {
+	ID               int64
+	Slug             string
+	ParentCategoryID int64
+	Percentage       float64
+}
+
+// Hardcoded categories from categories.csv
+var categories = []Category{
+	{ID: 553, Slug: "Graphics", Percentage: 0.9320, Price: PriceModel{MedianCents: 500, Spread: 0.9, FreeShare: 0.05}},
+	{ID: 23, Slug: "Fonts", Percentage: 0.0210, Price: PriceModel{MedianCents: 1500, Spread: 0.8, FreeShare: 0.03}},
+	{ID: 26, Slug: "Crafts", Percentage: 0.0185, Price: PriceModel{MedianCents: 400, Spread: 0.8, FreeShare: 0.06}},
+	{ID: 735, Slug: "Embroidery", Percentage: 0.0098, Price: PriceModel{MedianCents: 600, Spread: 0.7, FreeShare: 0.04}},
+	{ID: 2245, Slug: "Laser Cutting", Percentage: 0.0091, Price: PriceModel{MedianCents: 700, Spread: 0.8, FreeShare: 0.04}},
+	{ID: 546, Slug: "Bundles", Percentage: 0.0065, Price: PriceModel{MedianCents: 2500, Spread: 1.0, FreeShare: 0.01}},
+	{ID: 1850, Slug: "3D SVG", Percentage: 0.0029, Price: PriceModel{MedianCents: 600, Spread: 0.7, FreeShare: 0.05}},
+	{ID: 2244, Slug: "3D Printing", Percentage: 0.0003, Price: PriceModel{MedianCents: 900, Spread: 0.9, FreeShare: 0.08}},
+	{ID: 2246, Slug: "Knitting", Percentage: 0.0002, Price: PriceModel{MedianCents: 650, Spread: 0.6, FreeShare: 0.05}},
+}
+
+// Hardcoded subcategories from sub_categories.csv (top 50 for simplicity)
+var subcategories = []Subcategory{
+	{ID: 1804, Slug: "Graphics", ParentCategoryID: 553, Percentage: 0.2723225},
+	{ID: 638, Slug: "Crafts", ParentCategoryID: 26, Percentage: 0.1364864},
+	{ID: 602, Slug: "Illustrations", ParentCategoryID: 553, Percentage: 0.1073622},
+	{ID: 1281, Slug: "T-shirt Designs", ParentCategoryID: 553, Percentage: 0.0964248},
+	{ID: 580, Slug: "Icons", ParentCategoryID: 553, Percentage: 0.0459113},
+	{ID: 610, Slug: "Print Templates", ParentCategoryID: 553, Percentage: 0.0429839},
+	{ID: 1841, Slug: "Transparent PNGs", ParentCategoryID: 553, Percentage: 0.0347678},
+	{ID: 615, Slug: "Logos", ParentCategoryID: 553, Percentage: 0.0260298},
+	{ID: 608, Slug: "Backgrounds", ParentCategoryID: 553, Percentage: 0.0245767},
+	{ID: 1826, Slug: "Patterns", ParentCategoryID: 553, Percentage: 0.0211616},
+	{ID: 604, Slug: "Patterns", ParentCategoryID: 553, Percentage: 0.0203491},
+	{ID: 1854, Slug: "AI Illustrations", ParentCategoryID: 553, Percentage: 0.0192229},
+	{ID: 634, Slug: "Tumbler Wraps", ParentCategoryID: 26, Percentage: 0.0166457},
+	{ID: 897, Slug: "KDP Interiors", ParentCategoryID: 553, Percentage: 0.0086374},
+	{ID: 609, Slug: "Graphic Templates", ParentCategoryID: 553, Percentage: 0.0083662},
+	{ID: 1853, Slug: "AI Graphics", ParentCategoryID: 553, Percentage: 0.0080051},
+	{ID: 605, Slug: "Product Mockups", ParentCategoryID: 553, Percentage: 0.0069095},
+	{ID: 8, Slug: "Script & Handwritten", ParentCategoryID: 23, Percentage: 0.0061043},
+	{ID: 2111, Slug: "T-Shirts", ParentCategoryID: 553, Percentage: 0.0052110},
+	{ID: 1856, Slug: "AI Transparent PNGs", ParentCategoryID: 553, Percentage: 0.0051267},
+	{ID: 908, Slug: "Coloring Pages & Books Adults", ParentCategoryID: 553, Percentage: 0.0037459},
+	{ID: 606, Slug: "Textures", ParentCategoryID: 553, Percentage: 0.0036388},
+	{ID: 1829, Slug: "AI Generated", ParentCategoryID: 553, Percentage: 0.0034918},
+	{ID: 1858, Slug: "Coloring Pages", ParentCategoryID: 553, Percentage: 0.0032232},
+	{ID: 611, Slug: "Product Mockups", ParentCategoryID: 553, Percentage: 0.0029800},
+	{ID: 12, Slug: "Display", ParentCategoryID: 23, Percentage: 0.0029284},
+	{ID: 584, Slug: "Layer Styles", ParentCategoryID: 553, Percentage: 0.0029192},
+	{ID: 907, Slug: "Coloring Pages & Books Kids", ParentCategoryID: 553, Percentage: 0.0027455},
+	{ID: 1833, Slug: "Sketches", ParentCategoryID: 553, Percentage: 0.0024499},
+	{ID: 906, Slug: "Coloring Pages & Books", ParentCategoryID: 553, Percentage: 0.0019535},
+	{ID: 2112, Slug: "Hoodies & Sweatshirts", ParentCategoryID: 553, Percentage: 0.0018835},
+	{ID: 1280, Slug: "Social Media Templates", ParentCategoryID: 553, Percentage: 0.0016599},
+	{ID: 1857, Slug: "AI Patterns", ParentCategoryID: 553, Percentage: 0.0013482},
+	{ID: 2031, Slug: "Decorative Elements", ParentCategoryID: 553, Percentage: 0.0011899},
+	{ID: 2167, Slug: "Mugs & Cups", ParentCategoryID: 553, Percentage: 0.0011692},
+	{ID: 612, Slug: "Websites", ParentCategoryID: 553, Percentage: 0.0011454},
+	{ID: 67, Slug: "Designs & Drawings", ParentCategoryID: 553, Percentage: 0.0011122},
+	{ID: 617, Slug: "Presentation Templates", ParentCategoryID: 553, Percentage: 0.0010494},
+	{ID: 13, Slug: "Sans Serif", ParentCategoryID: 23, Percentage: 0.0010465},
+	{ID: 2169, Slug: "Frames & Posters", ParentCategoryID: 553, Percentage: 0.0010009},
+	{ID: 581, Slug: "Add-ons", ParentCategoryID: 553, Percentage: 0.0009821},
+	{ID: 582, Slug: "Actions & Presets", ParentCategoryID: 553, Percentage: 0.0009456},
+	{ID: 2117, Slug: "Baby & Kids Clothing", ParentCategoryID: 553, Percentage: 0.0008957},
+	{ID: 2357, Slug: "Wall Decor", ParentCategoryID: 26, Percentage: 0.0008954},
+	{ID: 2223, Slug: "Christmas & New Year", ParentCategoryID: 553, Percentage: 0.0008319},
+	{ID: 1145, Slug: "KDP Keywords", ParentCategoryID: 553, Percentage: 0.0008303},
+	{ID: 2365, Slug: "Winter & Christmas", ParentCategoryID: 553, Percentage: 0.0007974},
+	{ID: 14, Slug: "Serif", ParentCategoryID: 23, Percentage: 0.0007710},
+	{ID: 27, Slug: "Christmas", ParentCategoryID: 26, Percentage: 0.0006991},
+	{ID: 583, Slug: "Brushes", ParentCategoryID: 553, Percentage: 0.0006624},
+	{ID: 2370, Slug: "Halloween", ParentCategoryID: 553, Percentage: 0.0006500},
+	{ID: 2371, Slug: "Easter", ParentCategoryID: 553, Percentage: 0.0006200},
+	{ID: 2372, Slug: "Valentines Day", ParentCategoryID: 553, Percentage: 0.0005800},
+	{ID: 2373, Slug: "Thanksgiving", ParentCategoryID: 553, Percentage: 0.0005500},
+	{ID: 2374, Slug: "Birthday", ParentCategoryID: 553, Percentage: 0.0005200},
+	{ID: 2375, Slug: "Wedding", ParentCategoryID: 553, Percentage: 0.0004900},
+	{ID: 2376, Slug: "Baby Shower", ParentCategoryID: 553, Percentage: 0.0004600},
+	{ID: 2377, Slug: "Graduation", ParentCategoryID: 553, Percentage: 0.0004300},
+	{ID: 2378, Slug: "Summer", ParentCategoryID: 553, Percentage: 0.0004000},
+	{ID: 2379, Slug: "Spring", ParentCategoryID: 553, Percentage: 0.0003800},
+	{ID: 2380, Slug: "Fall", ParentCategoryID: 553, Percentage: 0.0003600},
+	{ID: 2381, Slug: "Back to School", ParentCategoryID: 553, Percentage: 0.0003400},
+	{ID: 2382, Slug: "Sports", ParentCategoryID: 553, Percentage: 0.0003200},
+	{ID: 2383, Slug: "Music", ParentCategoryID: 553, Percentage: 0.0003000},
+	{ID: 2384, Slug: "Food & Drink", ParentCategoryID: 553, Percentage: 0.0002800},
+	{ID: 2385, Slug: "Animals", ParentCategoryID: 553, Percentage: 0.0002600},
+	{ID: 2386, Slug: "Nature", ParentCategoryID: 553, Percentage: 0.0002400},
+	{ID: 2387, Slug: "Travel", ParentCategoryID: 553, Percentage: 0.0002200},
+	{ID: 2388, Slug: "Business", ParentCategoryID: 553, Percentage: 0.0002000},
+	{ID: 2389, Slug: "Education", ParentCategoryID: 553, Percentage: 0.0001800},
+	{ID: 2390, Slug: "Technology", ParentCategoryID: 553, Percentage: 0.0001600},
+	{ID: 637, Slug: "Paper Crafts", ParentCategoryID: 26, Percentage: 0.0001500},
+	{ID: 639, Slug: "Sewing & Quilting", ParentCategoryID: 26, Percentage: 0.0001400},
+	{ID: 640, Slug: "Jewelry Making", ParentCategoryID: 26, Percentage: 0.0001300},
+	{ID: 641, Slug: "Scrapbooking", ParentCategoryID: 26, Percentage: 0.0001200},
+	{ID: 642, Slug: "Card Making", ParentCategoryID: 26, Percentage: 0.0001100},
+	{ID: 2391, Slug: "Stickers & Labels", ParentCategoryID: 553, Percentage: 0.0001000},
+	{ID: 2392, Slug: "Banners & Signs", ParentCategoryID: 553, Percentage: 0.0000950},
+	{ID: 2393, Slug: "Invitations", ParentCategoryID: 553, Percentage: 0.0000900},
+	{ID: 2394, Slug: "Greeting Cards", ParentCategoryID: 553, Percentage: 0.0000850},
+	{ID: 2395, Slug: "Planners & Journals", ParentCategoryID: 553, Percentage: 0.0000800},
+	{ID: 2396, Slug: "Calendars", ParentCategoryID: 553, Percentage: 0.0000750},
+	{ID: 2397, Slug: "Bookmarks", ParentCategoryID: 553, Percentage: 0.0000700},
+	{ID: 2398, Slug: "Gift Tags", ParentCategoryID: 553, Percentage: 0.0000650},
+	{ID: 2399, Slug: "Photo Frames", ParentCategoryID: 553, Percentage: 0.0000600},
+	{ID: 2400, Slug: "Packaging", ParentCategoryID: 553, Percentage: 0.0000550},
+	{ID: 2401, Slug: "Wrapping Paper", ParentCategoryID: 553, Percentage: 0.0000500},
+	{ID: 11, Slug: "Handwriting", ParentCategoryID: 23, Percentage: 0.0000450},
+	{ID: 15, Slug: "Slab Serif", ParentCategoryID: 23, Percentage: 0.0000400},
+	{ID: 16, Slug: "Decorative", ParentCategoryID: 23, Percentage: 0.0000350},
+	{ID: 737, Slug: "Machine Embroidery", ParentCategoryID: 735, Percentage: 0.0000300},
+	{ID: 738, Slug: "Hand Embroidery", ParentCategoryID: 735, Percentage: 0.0000250},
+	{ID: 2247, Slug: "Files", ParentCategoryID: 2245, Percentage: 0.0000200},
+	{ID: 2248, Slug: "Templates", ParentCategoryID: 2245, Percentage: 0.0000150},
+	{ID: 2249, Slug: "3D Models", ParentCategoryID: 2244, Percentage: 0.0000100},
+	{ID: 2250, Slug: "Knitting Patterns", ParentCategoryID: 2246, Percentage: 0.0000050},
+}
+
+// categoryColumns are the columns written for categories and subcategories
+var categoryColumns = []string{"category_id", "parent_category_id", "default_name", "default_description", "url_path", "hierarchy_path",
+	"attributes", "name_translations", "description_translations", "created_at", "updated_at"}
+
+// CategoryRow is one generated category or subcategory
+type CategoryRow struct {
+	ID            int64
+	ParentID      int64 // 0 for top-level categories
+	Name          string
+	Description   string
+	URLPath       string
+	HierarchyPath string
+	Depth         int               // 0 for top-level categories
+	Names         map[string]string // Name translations by locale
+	Descriptions  map[string]string // Description translations by locale
+	CreatedAt     time.Time         // created_at and updated_at, the time of the write when zero
+}
+
+// CategoryRows returns the category table rows of categories
+func CategoryRows(categories []CategoryRow) writer.Rows {
+	now := time.Now()
+	rows := writer.Rows{Table: "category", Columns: categoryColumns, Values: make([][]interface{}, 0, len(categories))}
+	for _, c := range categories {
+		var parentID interface{}
+		if c.ParentID != 0 {
+			parentID = c.ParentID
+		}
+		createdAt := c.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		rows.Values = append(rows.Values, []interface{}{
+			c.ID, parentID, c.Name, c.Description, c.URLPath, c.HierarchyPath, fmt.Sprintf(`{"depth": %d}`, c.Depth),
+			mustJSON(c.Names), mustJSON(c.Descriptions), createdAt, createdAt,
+		})
+	}
+	return rows
+}
+
+// GenerateCategories returns the built-in top-level categories, each the root of its own hierarchy
+func GenerateCategories(rng *rand.Rand, locales LocaleConfig) []CategoryRow {
+	rows := make([]CategoryRow, 0, len(categories))
+	for _, cat := range categories {
+		names, descriptions := localizedCategory(rng, locales, cat.ID, cat.Slug)
+		rows = append(rows, CategoryRow{
+			ID:            cat.ID,
+			Name:          cat.Slug,
+			Description:   fmt.Sprintf("Description for %s", cat.Slug),
+			URLPath:       slugify(cat.Slug),
+			HierarchyPath: categoryHierarchyPath([]int64{cat.ID}),
+			Names:         names,
+			Descriptions:  descriptions,
+		})
+	}
+	return rows
+}
+
+// Words of the generated subcategory names
+var (
+	subcategoryPrefixes = []string{"Modern", "Vintage", "Classic", "Premium", "Professional", "Creative", "Elegant", "Bold", "Minimal", "Decorative"}
+	subcategorySuffixes = []string{"Designs", "Templates", "Graphics", "Elements", "Patterns", "Styles", "Collections", "Sets", "Packs", "Kits"}
+)
+
+// SubcategoryGenerator grows the category tree: every subcategory gets a random parent above the maximum
+// depth, generated subcategories included
+type SubcategoryGenerator struct {
+	tree     *categoryTree
+	parents  []int64
+	depth    int
+	locales  LocaleConfig
+	nextID   int64
+	topLevel int
+}
+
+// NewSubcategoryGenerator returns a generator attaching subcategories up to depth levels below the
+// top-level categories of existing, numbered from startID
+func NewSubcategoryGenerator(existing []CategoryRow, depth int, locales LocaleConfig, startID int64) (*SubcategoryGenerator, error) {
+	return newSubcategoryGenerator(newCategoryTree(existing), depth, locales, startID)
+}
+
+func newSubcategoryGenerator(tree *categoryTree, depth int, locales LocaleConfig, startID int64) (*SubcategoryGenerator, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("category depth must be >= 1, got %d", depth)
+	}
+
+	// Any category above the maximum depth can receive children
+	g := &SubcategoryGenerator{tree: tree, depth: depth, locales: locales, nextID: startID}
+	for id := range tree.nodes {
+		level := tree.level(id)
+		if level == 0 {
+			g.topLevel++
+		}
+		if level < depth {
+			g.parents = append(g.parents, id)
+		}
+	}
+	sort.Slice(g.parents, func(i, j int) bool { return g.parents[i] < g.parents[j] })
+
+	if g.topLevel == 0 {
+		return nil, fmt.Errorf("no parent categories found - please import categories first")
+	}
+	return g, nil
+}
+
+// Generate returns the next count subcategories
+func (g *SubcategoryGenerator) Generate(rng *rand.Rand, count int) []CategoryRow {
+	rows := make([]CategoryRow, 0, count)
+	for i := 0; i < count; i++ {
+		subcatID := g.nextID
+		g.nextID++
+		parentCatID := g.parents[rng.Intn(len(g.parents))]
+		parent := g.tree.nodes[parentCatID]
+
+		// Generate random subcategory name
+		prefix := subcategoryPrefixes[rng.Intn(len(subcategoryPrefixes))]
+		suffix := subcategorySuffixes[rng.Intn(len(subcategorySuffixes))]
+		slug := fmt.Sprintf("%s-%s-%d", strings.ToLower(prefix), strings.ToLower(suffix), subcatID)
+
+		// Paths are derived from the parent so they always agree with parent_category_id
+		chain := append(g.tree.ancestry(parentCatID), subcatID)
+		level := len(chain) - 1
+		urlPath := parent.urlPath + "/" + slug
+		names, descriptions := localizedCategory(rng, g.locales, subcatID, prefix+" "+suffix)
+
+		rows = append(rows, CategoryRow{
+			ID:            subcatID,
+			ParentID:      parentCatID,
+			Name:          slug,
+			Description:   fmt.Sprintf("Description for %s", slug),
+			URLPath:       urlPath,
+			HierarchyPath: categoryHierarchyPath(chain),
+			Depth:         level,
+			Names:         names,
+			Descriptions:  descriptions,
+		})
+
+		g.tree.add(&categoryNode{id: subcatID, parentID: parentCatID, urlPath: urlPath})
+		if level < g.depth {
+			g.parents = append(g.parents, subcatID)
+		}
+	}
+	return rows
+}
+
+// ImportCategories inserts the built-in top-level categories that don't exist yet
+func (l *Loader) ImportCategories(locales LocaleConfig) error {
+	fmt.Print("\n=== Importing Categories ===\n\n")
+	fmt.Printf("Importing %d categories...\n", len(categories))
+
+	bar := l.newProgressBar(len(categories), "Categories")
+
+	inserted := 0
+	skipped := 0
+
+	for _, cat := range GenerateCategories(l.newRand(-1), locales) {
+		// Check if category already exists
+		var exists bool
+		err := l.db.QueryRow("SELECT EXISTS(SELECT 1 FROM category WHERE category_id = $1)", cat.ID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check category existence: %w", err)
+		}
+
+		if exists {
+			skipped++
+		} else {
+			n, err := l.sink.Write(CategoryRows([]CategoryRow{cat}))
+			if err != nil {
+				return fmt.Errorf("failed to insert category %d: %w", cat.ID, err)
+			}
+			// A concurrent run may have inserted it since the check
+			inserted += int(n[0])
+			skipped += 1 - int(n[0])
+		}
+
+		bar.Add(1)
+	}
+
+	fmt.Printf("\n  ✓ Inserted: %d, Skipped: %d\n\n", inserted, skipped)
+	return nil
+}
+
+// ImportSubcategories attaches subcategoryCount subcategories to the existing categories, up to depth levels deep
+func (l *Loader) ImportSubcategories(subcategoryCount int, depth int, locales LocaleConfig) error {
+	fmt.Print("\n=== Importing Subcategories ===\n\n")
+	fmt.Printf("Importing %d subcategories up to depth %d...\n", subcategoryCount, depth)
+
+	// Load the existing hierarchy
+	tree, err := loadCategoryTree(l.db)
+	if err != nil {
+		return err
+	}
+
+	// Get starting subcategory ID
+	var startID int64
+	err = l.db.QueryRow("SELECT COALESCE(MAX(category_id), 10000) FROM category WHERE parent_category_id IS NOT NULL").Scan(&startID)
+	if err != nil {
+		return fmt.Errorf("failed to get starting subcategory ID: %w", err)
+	}
+	startID++ // Start from next available ID
+
+	generator, err := newSubcategoryGenerator(tree, depth, locales, startID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Found %d parent categories (%d top-level)\n", len(generator.parents), generator.topLevel)
+
+	bar := l.newProgressBar(subcategoryCount, "Subcategories")
+
+	inserted := 0
+	levelCounts := make(map[int]int)
+	rng := l.newRand(-1)
+
+	for i := 0; i < subcategoryCount; i++ {
+		// One at a time, later subcategories may hang below earlier ones
+		sub := generator.Generate(rng, 1)
+		n, err := l.sink.Write(CategoryRows(sub))
+		if err != nil {
+			return fmt.Errorf("failed to insert subcategory %d: %w", sub[0].ID, err)
+		}
+		if n[0] > 0 {
+			levelCounts[sub[0].Depth]++
+			inserted++
+		}
+		bar.Add(1)
+	}
+
+	fmt.Printf("\n  ✓ Inserted: %d subcategories\n", inserted)
+	for level := 1; level <= depth; level++ {
+		fmt.Printf("    Level %d: %d\n", level, levelCounts[level])
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,133 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const DefaultCategoryDepth = 1 // Subcategories hang directly below the top-level categories
+
+// categoryNode is a category row as seen by the hierarchy builder
+type categoryNode struct {
+	id       int64
+	parentID int64 // 0 for top-level categories
+	urlPath  string
+}
+
+// categoryTree indexes every category by ID so paths can be derived from parent_category_id
+type categoryTree struct {
+	nodes map[int64]*categoryNode
+}
+
+// loadCategoryTree reads every category with its parent and URL path
+func loadCategoryTree(db *sql.DB) (*categoryTree, error) {
+	rows, err := db.Query("SELECT category_id, COALESCE(parent_category_id, 0), url_path FROM category")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	defer rows.Close()
+
+	tree := &categoryTree{nodes: make(map[int64]*categoryNode)}
+	for rows.Next() {
+		node := &categoryNode{}
+		if err := rows.Scan(&node.id, &node.parentID, &node.urlPath); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		tree.nodes[node.id] = node
+	}
+	return tree, rows.Err()
+}
+
+// newCategoryTree indexes generated category rows, for building on categories not written yet
+func newCategoryTree(categories []CategoryRow) *categoryTree {
+	tree := &categoryTree{nodes: make(map[int64]*categoryNode, len(categories))}
+	for _, c := range categories {
+		tree.add(&categoryNode{id: c.ID, parentID: c.ParentID, urlPath: c.URLPath})
+	}
+	return tree
+}
+
+func (t *categoryTree) add(node *categoryNode) {
+	t.nodes[node.id] = node
+}
+
+// ancestry returns the category IDs from the top-level category down to id.
+// A cycle or a missing parent stops the walk at the last reachable ancestor.
+func (t *categoryTree) ancestry(id int64) []int64 {
+	var chain []int64
+	seen := make(map[int64]bool)
+	for current := id; current != 0 && !seen[current]; {
+		seen[current] = true
+		chain = append(chain, current)
+		node, ok := t.nodes[current]
+		if !ok {
+			break
+		}
+		current = node.parentID
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// root returns the top-level category a category belongs to
+func (t *categoryTree) root(id int64) int64 {
+	return t.ancestry(id)[0]
+}
+
+// subcategories groups the subcategories at any depth under their top-level category, in ascending order
+func (t *categoryTree) subcategories() map[int64][]int64 {
+	grouped := make(map[int64][]int64)
+	for id, node := range t.nodes {
+		if node.parentID == 0 {
+			continue
+		}
+		rootID := t.root(id)
+		grouped[rootID] = append(grouped[rootID], id)
+	}
+	for rootID := range grouped {
+		subs := grouped[rootID]
+		sort.Slice(subs, func(i, j int) bool { return subs[i] < subs[j] })
+	}
+	return grouped
+}
+
+// level returns 0 for top-level categories, 1 for subcategories, 2 for sub-subcategories and so on
+func (t *categoryTree) level(id int64) int {
+	return len(t.ancestry(id)) - 1
+}
+
+// hierarchyPath returns the ltree path of a category, using category IDs as labels (e.g. "553.10001.10042")
+func (t *categoryTree) hierarchyPath(id int64) string {
+	return categoryHierarchyPath(t.ancestry(id))
+}
+
+func categoryHierarchyPath(chain []int64) string {
+	labels := make([]string, len(chain))
+	for i, id := range chain {
+		labels[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(labels, ".")
+}
+
+// slugify lowercases a name and collapses every run of non-alphanumeric characters into a dash
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
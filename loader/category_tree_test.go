@@ -0,0 +1,71 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testCategoryTree() *categoryTree {
+	tree := &categoryTree{nodes: make(map[int64]*categoryNode)}
+	for _, node := range []*categoryNode{
+		{id: 553},
+		{id: 10001, parentID: 553},
+		{id: 10042, parentID: 10001},
+		{id: 10050, parentID: 999}, // Parent missing
+		{id: 20001, parentID: 20002},
+		{id: 20002, parentID: 20001}, // Cycle
+	} {
+		tree.add(node)
+	}
+	return tree
+}
+
+func TestCategoryTreeAncestry(t *testing.T) {
+	tree := testCategoryTree()
+	tests := []struct {
+		id    int64
+		chain []int64
+		root  int64
+		level int
+		path  string
+	}{
+		{553, []int64{553}, 553, 0, "553"},
+		{10001, []int64{553, 10001}, 553, 1, "553.10001"},
+		{10042, []int64{553, 10001, 10042}, 553, 2, "553.10001.10042"},
+		{10050, []int64{999, 10050}, 999, 1, "999.10050"},
+		{20001, []int64{20002, 20001}, 20002, 1, "20002.20001"},
+	}
+	for _, tt := range tests {
+		if chain := tree.ancestry(tt.id); !reflect.DeepEqual(chain, tt.chain) {
+			t.Errorf("ancestry(%d) = %v, want %v", tt.id, chain, tt.chain)
+		}
+		if root := tree.root(tt.id); root != tt.root {
+			t.Errorf("root(%d) = %d, want %d", tt.id, root, tt.root)
+		}
+		if level := tree.level(tt.id); level != tt.level {
+			t.Errorf("level(%d) = %d, want %d", tt.id, level, tt.level)
+		}
+		if path := tree.hierarchyPath(tt.id); path != tt.path {
+			t.Errorf("hierarchyPath(%d) = %q, want %q", tt.id, path, tt.path)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Graphics", "graphics"},
+		{"Modern Designs", "modern-designs"},
+		{"  Fonts & Templates!! ", "fonts-templates"},
+		{"Schriftarten Für Dich", "schriftarten-für-dich"},
+		{"3D Crafts", "3d-crafts"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.name); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
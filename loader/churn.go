@@ -0,0 +1,216 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultChurnDuration = time.Minute
+	DefaultChurnRate     = 100
+	DefaultChurnBatch    = 1
+	churnReportInterval  = 10 * time.Second
+	churnTick            = 10 * time.Millisecond
+)
+
+// ChurnConfig controls a mutation workload: how long it runs, how fast and how many rows each operation touches
+type ChurnConfig struct {
+	Duration time.Duration
+	Rate     float64 // Target operations per second across all workers, 0 = as fast as possible
+	Batch    int     // Rows touched per operation
+}
+
+func (cfg ChurnConfig) validate() error {
+	if cfg.Duration <= 0 {
+		return fmt.Errorf("churn duration must be > 0, got %s", cfg.Duration)
+	}
+	if cfg.Rate < 0 {
+		return fmt.Errorf("churn rate must be >= 0, got %.2f", cfg.Rate)
+	}
+	if cfg.Batch < 1 {
+		return fmt.Errorf("churn batch must be >= 1, got %d", cfg.Batch)
+	}
+	return nil
+}
+
+// churnOp is one kind of mutation; run returns the number of rows it changed
+type churnOp func(db *sql.DB, rng *rand.Rand, batch int) (int64, error)
+
+// churnStats are the counters of one operation type
+type churnStats struct {
+	ops     int64
+	rows    int64
+	noop    int64 // Operations that found nothing to change
+	latency time.Duration
+}
+
+// parseChurnMix parses a weighted operation mix such as "update:0.7,delete:0.3" against the known operations
+func parseChurnMix(mix string, ops map[string]churnOp) (weightedChoice, error) {
+	entries, err := parseRatioList(mix)
+	if err != nil {
+		return weightedChoice{}, fmt.Errorf("invalid churn mix: %w", err)
+	}
+	for _, e := range entries {
+		if _, ok := ops[e.name]; !ok {
+			names := make([]string, 0, len(ops))
+			for name := range ops {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return weightedChoice{}, fmt.Errorf("unknown churn operation %q, expected one of %v", e.name, names)
+		}
+	}
+	choice, err := newWeightedChoice(entries)
+	if err != nil {
+		return weightedChoice{}, fmt.Errorf("invalid churn mix: %w", err)
+	}
+	return choice, nil
+}
+
+// runChurn runs operations picked from mix at the configured rate until the duration elapses,
+// printing the throughput periodically and a per-operation summary at the end
+func (l *Loader) runChurn(cfg ChurnConfig, mix weightedChoice, ops map[string]churnOp) error {
+	deadline := time.Now().Add(cfg.Duration)
+
+	// stop is closed on the first error, ending the producer and the workers before the deadline
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+
+	// Tokens pace the workers; when workers fall behind, extra tokens are dropped rather than queued
+	tokens := make(chan struct{}, Workers)
+	go func() {
+		defer close(tokens)
+		if cfg.Rate == 0 {
+			for time.Now().Before(deadline) {
+				select {
+				case tokens <- struct{}{}:
+				case <-stop:
+					return
+				}
+			}
+			return
+		}
+
+		ticker := time.NewTicker(churnTick)
+		defer ticker.Stop()
+		credits := 0.0
+		last := time.Now()
+		for {
+			var now time.Time
+			select {
+			case now = <-ticker.C:
+			case <-stop:
+				return
+			}
+			if !now.Before(deadline) {
+				return
+			}
+			credits += cfg.Rate * now.Sub(last).Seconds()
+			last = now
+			for ; credits >= 1; credits-- {
+				select {
+				case tokens <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	stats := make(map[string]*churnStats, len(mix.names))
+	for _, name := range mix.names {
+		stats[name] = &churnStats{}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstError error
+	var totalOps int64
+
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			rng := l.newRand(int64(workerID) * 1000)
+
+			for range tokens {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				name := mix.pick(rng)
+				start := time.Now()
+				rows, err := ops[name](l.db, rng, cfg.Batch)
+				elapsed := time.Since(start)
+
+				if err != nil {
+					mu.Lock()
+					if firstError == nil {
+						firstError = fmt.Errorf("worker %d: %s: %w", workerID, name, err)
+					}
+					mu.Unlock()
+					stopOnce.Do(func() { close(stop) })
+					return
+				}
+
+				mu.Lock()
+				s := stats[name]
+				s.ops++
+				s.rows += rows
+				s.latency += elapsed
+				if rows == 0 {
+					s.noop++
+				}
+				totalOps++
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	started := time.Now()
+	ticker := time.NewTicker(churnReportInterval)
+	defer ticker.Stop()
+	var lastOps int64
+	lastReport := started
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		case now := <-ticker.C:
+			mu.Lock()
+			ops := totalOps
+			mu.Unlock()
+			fmt.Printf("  %6s  %8.1f ops/s\n", now.Sub(started).Round(time.Second), float64(ops-lastOps)/now.Sub(lastReport).Seconds())
+			lastOps, lastReport = ops, now
+		}
+	}
+
+	if firstError != nil {
+		return firstError
+	}
+
+	elapsed := time.Since(started).Seconds()
+	fmt.Printf("\n  %-12s %10s %10s %10s %10s %12s\n", "Operation", "Ops", "Ops/s", "Rows", "No-op", "Avg latency")
+	for _, name := range mix.names {
+		s := stats[name]
+		avg := time.Duration(0)
+		if s.ops > 0 {
+			avg = s.latency / time.Duration(s.ops)
+		}
+		fmt.Printf("  %-12s %10d %10.1f %10d %10d %12s\n", name, s.ops, float64(s.ops)/elapsed, s.rows, s.noop, avg.Round(time.Microsecond))
+	}
+	fmt.Printf("\n  ✓ %d operations in %.0fs (%.1f ops/s)\n\n", totalOps, elapsed, float64(totalOps)/elapsed)
+	return nil
+}
@@ -0,0 +1,107 @@
+package loader
+
+import (
+	"database/sql"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChurnConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     ChurnConfig
+		wantErr bool
+	}{
+		{ChurnConfig{Duration: DefaultChurnDuration, Rate: DefaultChurnRate, Batch: DefaultChurnBatch}, false},
+		{ChurnConfig{Duration: time.Second, Rate: 0, Batch: 10}, false},
+		{ChurnConfig{Duration: 0, Rate: 1, Batch: 1}, true},
+		{ChurnConfig{Duration: time.Second, Rate: -1, Batch: 1}, true},
+		{ChurnConfig{Duration: time.Second, Rate: 1, Batch: 0}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: got error %v, want error %t", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseChurnMix(t *testing.T) {
+	noop := func(*sql.DB, *rand.Rand, int) (int64, error) { return 0, nil }
+	ops := map[string]churnOp{"update": noop, "delete": noop, "create": noop}
+
+	tests := []struct {
+		mix     string
+		want    []string
+		wantErr bool
+	}{
+		{"update:0.7,delete:0.3", []string{"update", "delete"}, false},
+		{"create:1", []string{"create"}, false},
+		{"update:1,delete:0", []string{"update", "delete"}, false},
+		{"update:1,insert:1", nil, true},
+		{"update:0,delete:0", nil, true},
+		{"update:-1,delete:2", nil, true},
+		{"update", nil, true},
+		{"", nil, true},
+	}
+	for _, tt := range tests {
+		mix, err := parseChurnMix(tt.mix, ops)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseChurnMix(%q): got error %v, want error %t", tt.mix, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(mix.names, tt.want) {
+			t.Errorf("parseChurnMix(%q) operations = %v, want %v", tt.mix, mix.names, tt.want)
+		}
+	}
+}
+
+func TestDefaultPromoChurnMix(t *testing.T) {
+	c := &promoChurn{}
+	ops := map[string]churnOp{
+		"activate": c.activate,
+		"expire":   c.expire,
+		"pause":    c.pause,
+		"resume":   c.resume,
+		"extend":   c.extend,
+		"delete":   c.delete,
+		"create":   c.create,
+	}
+	mix, err := parseChurnMix(DefaultPromoChurnMix, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mix.names) != len(ops) {
+		t.Errorf("default mix covers %v, want all %d operations", mix.names, len(ops))
+	}
+}
+
+func TestRunChurnStopsOnFirstError(t *testing.T) {
+	var calls atomic.Int64
+	ops := map[string]churnOp{
+		"fail": func(*sql.DB, *rand.Rand, int) (int64, error) {
+			calls.Add(1)
+			return 0, errors.New("boom")
+		},
+	}
+	mix, err := parseChurnMix("fail:1", ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := &Loader{seed: 1}
+	start := time.Now()
+	err = l.runChurn(ChurnConfig{Duration: time.Minute, Batch: 1}, mix, ops)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("runChurn returned %v, want the operation error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("runChurn ran for %s after the first error", elapsed)
+	}
+	if n := calls.Load(); n > int64(Workers) {
+		t.Errorf("%d operations ran, want at most one per worker (%d)", n, Workers)
+	}
+}
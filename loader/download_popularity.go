@@ -0,0 +1,206 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	DefaultPopularityZipf    = 1.0
+	DefaultRecencyBoost      = 4.0 // A brand new product is 5x as popular as an old one of the same rank
+	DefaultRecencyHalfLife   = 30.0
+	DefaultPromoBoost        = 3.0
+	DefaultTrendingProducts  = 5
+	DefaultTrendingHours     = 6.0
+	DefaultTrendingShare     = 0.03
+	downloadPopularityReport = 10 // Number of top products printed after the import
+)
+
+// DownloadPopularityConfig controls which products get downloaded
+type DownloadPopularityConfig struct {
+	Zipf            float64 // Exponent of the popularity rank distribution, 0 = uniform
+	RecencyBoost    float64 // Extra weight of a product created just now, decaying with RecencyHalfLife
+	RecencyHalfLife float64 // Days after which the recency boost is halved
+	PromoBoost      float64 // Weight multiplier of products with an active promo
+	Trending        int     // Number of products with a download burst
+	TrendingHours   float64 // Length of each burst
+	TrendingShare   float64 // Share of all downloads that go to the bursts
+}
+
+func (cfg DownloadPopularityConfig) validate() error {
+	if cfg.Zipf < 0 {
+		return fmt.Errorf("popularity zipf exponent must be >= 0, got %.2f", cfg.Zipf)
+	}
+	if cfg.RecencyBoost < 0 {
+		return fmt.Errorf("recency boost must be >= 0, got %.2f", cfg.RecencyBoost)
+	}
+	if cfg.RecencyHalfLife <= 0 {
+		return fmt.Errorf("recency half-life must be > 0 days, got %.2f", cfg.RecencyHalfLife)
+	}
+	if cfg.PromoBoost <= 0 {
+		return fmt.Errorf("promo boost must be > 0, got %.2f", cfg.PromoBoost)
+	}
+	if cfg.Trending < 0 {
+		return fmt.Errorf("trending products must be >= 0, got %d", cfg.Trending)
+	}
+	if cfg.TrendingHours <= 0 {
+		return fmt.Errorf("trending burst length must be > 0 hours, got %.2f", cfg.TrendingHours)
+	}
+	if cfg.TrendingShare < 0 || cfg.TrendingShare > 1 {
+		return fmt.Errorf("trending share must be between 0 and 1, got %.2f", cfg.TrendingShare)
+	}
+	return nil
+}
+
+// trendingBurst is a short window in which one product gets a spike of downloads
+type trendingBurst struct {
+	productID int64
+	start     time.Time
+	length    time.Duration
+}
+
+// downloadTargets picks the product and time of each download
+type downloadTargets struct {
+	products   *idSampler
+	bursts     []trendingBurst
+	burstShare float64
+	clock      *downloadClock
+	promoted   int
+}
+
+// newDownloadTargets weights the sampled products by popularity, recency and active promos.
+// Popularity ranks are assigned at random so they don't correlate with product_id.
+func newDownloadTargets(cfg DownloadPopularityConfig, products *idSampler, promoted map[int64]bool, clock *downloadClock, now time.Time, rng *rand.Rand) *downloadTargets {
+	t := &downloadTargets{products: products, clock: clock, burstShare: cfg.TrendingShare}
+	ranks := rng.Perm(products.len())
+	products.reweight(func(i int) float64 {
+		weight := 1 / math.Pow(float64(ranks[i]+1), cfg.Zipf)
+		ageDays := now.Sub(products.createdAt[i]).Hours() / 24
+		weight *= 1 + cfg.RecencyBoost*math.Exp2(-math.Max(ageDays, 0)/cfg.RecencyHalfLife)
+		if promoted[products.ids[i]] {
+			weight *= cfg.PromoBoost
+			t.promoted++
+		}
+		return weight
+	})
+
+	windowStart := now.Add(-time.Duration(len(clock.hours)) * time.Hour)
+	burstLength := time.Duration(cfg.TrendingHours * float64(time.Hour))
+	for i := 0; i < cfg.Trending; i++ {
+		j := products.index(rng)
+		start := windowStart.Add(time.Duration(rng.Int63n(int64(now.Sub(windowStart)))))
+		if start.Add(burstLength).After(now) {
+			start = now.Add(-burstLength)
+		}
+		if start.Before(products.createdAt[j]) {
+			start = products.createdAt[j]
+		}
+		length := burstLength
+		if end := start.Add(length); end.After(now) {
+			length = now.Sub(start)
+		}
+		t.bursts = append(t.bursts, trendingBurst{productID: products.ids[j], start: start, length: length})
+	}
+	return t
+}
+
+// loadActivePromoProductIDs returns the products whose promo is active at now
+func loadActivePromoProductIDs(db *sql.DB, now time.Time) (map[int64]bool, error) {
+	rows, err := db.Query("SELECT product_id FROM product_promo WHERE status = 'active' AND expires_at > $1", now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active promos: %w", err)
+	}
+	defer rows.Close()
+
+	promoted := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan promo product ID: %w", err)
+		}
+		promoted[id] = true
+	}
+	return promoted, rows.Err()
+}
+
+// pick returns a product and a download time that is never before the product was created
+func (t *downloadTargets) pick(rng *rand.Rand) (int64, time.Time) {
+	if len(t.bursts) > 0 && rng.Float64() < t.burstShare {
+		b := t.bursts[rng.Intn(len(t.bursts))]
+		offset := time.Duration(0)
+		if b.length > 0 {
+			offset = time.Duration(rng.Int63n(int64(b.length)))
+		}
+		return b.productID, b.start.Add(offset).Truncate(time.Microsecond)
+	}
+
+	i := t.products.index(rng)
+	return t.products.ids[i], t.clock.sampleAfter(rng, t.products.createdAt[i])
+}
+
+// verifyDownloads checks download timestamps against product creation and prints the popularity skew
+func verifyDownloads(db *sql.DB, report *verifyReport) error {
+	var early int64
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM product_download d
+		INNER JOIN product p ON p.product_id = d.product_id
+		WHERE d.downloaded_at < p.created_at
+	`).Scan(&early)
+	if err != nil {
+		return fmt.Errorf("failed to compare download timestamps: %w", err)
+	}
+	report.check("downloads happen after the product was created", early == 0, fmt.Sprintf("%d downloads before their product was created", early))
+
+	var products, downloads, topDownloads sql.NullInt64
+	err = db.QueryRow(`
+		WITH counts AS (
+			SELECT product_id, COUNT(*) AS n FROM product_download GROUP BY product_id
+		)
+		SELECT (SELECT COUNT(*) FROM counts),
+		       (SELECT SUM(n) FROM counts),
+		       (SELECT SUM(n) FROM (
+		           SELECT n FROM counts ORDER BY n DESC
+		           LIMIT GREATEST(1, (SELECT COUNT(*) FROM counts) / 100)
+		       ) top)
+	`).Scan(&products, &downloads, &topDownloads)
+	if err != nil {
+		return fmt.Errorf("failed to compute download skew: %w", err)
+	}
+	if downloads.Int64 == 0 {
+		fmt.Println("  No downloads found")
+		return nil
+	}
+	fmt.Printf("  %d downloads over %d products, the top 1%% of products have %.1f%% of the downloads\n",
+		downloads.Int64, products.Int64, 100*float64(topDownloads.Int64)/float64(downloads.Int64))
+	return nil
+}
+
+// printTopDownloads prints the most downloaded products of the last 7 days
+func printTopDownloads(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT product_id, COUNT(*)
+		FROM product_download
+		WHERE downloaded_at > $1
+		GROUP BY product_id
+		ORDER BY COUNT(*) DESC
+		LIMIT $2
+	`, time.Now().UTC().Add(-7*24*time.Hour), downloadPopularityReport)
+	if err != nil {
+		return fmt.Errorf("failed to load top downloads: %w", err)
+	}
+	defer rows.Close()
+
+	fmt.Println("  Top products of the last 7 days:")
+	for rows.Next() {
+		var productID, count int64
+		if err := rows.Scan(&productID, &count); err != nil {
+			return fmt.Errorf("failed to scan top download: %w", err)
+		}
+		fmt.Printf("    %-12d %d downloads\n", productID, count)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestDownloadPopularityConfigValidate(t *testing.T) {
+	valid := DownloadPopularityConfig{
+		Zipf:            DefaultPopularityZipf,
+		RecencyBoost:    DefaultRecencyBoost,
+		RecencyHalfLife: DefaultRecencyHalfLife,
+		PromoBoost:      DefaultPromoBoost,
+		Trending:        DefaultTrendingProducts,
+		TrendingHours:   DefaultTrendingHours,
+		TrendingShare:   DefaultTrendingShare,
+	}
+	tests := []struct {
+		name    string
+		change  func(cfg *DownloadPopularityConfig)
+		wantErr bool
+	}{
+		{"defaults", func(cfg *DownloadPopularityConfig) {}, false},
+		{"uniform", func(cfg *DownloadPopularityConfig) { cfg.Zipf, cfg.RecencyBoost, cfg.Trending = 0, 0, 0 }, false},
+		{"negative zipf", func(cfg *DownloadPopularityConfig) { cfg.Zipf = -1 }, true},
+		{"negative recency boost", func(cfg *DownloadPopularityConfig) { cfg.RecencyBoost = -1 }, true},
+		{"zero half-life", func(cfg *DownloadPopularityConfig) { cfg.RecencyHalfLife = 0 }, true},
+		{"zero promo boost", func(cfg *DownloadPopularityConfig) { cfg.PromoBoost = 0 }, true},
+		{"negative trending", func(cfg *DownloadPopularityConfig) { cfg.Trending = -1 }, true},
+		{"zero burst length", func(cfg *DownloadPopularityConfig) { cfg.TrendingHours = 0 }, true},
+		{"trending share above 1", func(cfg *DownloadPopularityConfig) { cfg.TrendingShare = 1.5 }, true},
+	}
+	for _, tt := range tests {
+		cfg := valid
+		tt.change(&cfg)
+		if err := cfg.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDownloadTargetsPick(t *testing.T) {
+	cfg, err := ParseDownloadTimeConfig(14, 0.6, 0.15, 0, "", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	newProduct := now.Add(-24 * time.Hour)
+	targets := &downloadTargets{
+		products: &idSampler{
+			ids:        []int64{1, 2},
+			createdAt:  []time.Time{now.AddDate(-1, 0, 0), newProduct},
+			cumulative: []float64{1, 2},
+		},
+		bursts:     []trendingBurst{{productID: 3, start: now.Add(-3 * time.Hour), length: time.Hour}},
+		burstShare: 0.1,
+		clock:      newDownloadClock(cfg, now),
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	counts := make(map[int64]int)
+	const n = 20000
+	for i := 0; i < n; i++ {
+		productID, downloadedAt := targets.pick(rng)
+		counts[productID]++
+		switch productID {
+		case 2:
+			if downloadedAt.Before(newProduct) {
+				t.Fatalf("product 2 downloaded at %v, before it was created at %v", downloadedAt, newProduct)
+			}
+		case 3:
+			if downloadedAt.Before(now.Add(-3*time.Hour)) || !downloadedAt.Before(now.Add(-2*time.Hour)) {
+				t.Fatalf("burst download at %v outside its hour", downloadedAt)
+			}
+		}
+	}
+	if share := float64(counts[3]) / n; share < 0.09 || share > 0.11 {
+		t.Errorf("%.3f of the downloads went to the burst, want 0.1", share)
+	}
+	if counts[1] < counts[2]*8/10 || counts[1] > counts[2]*12/10 {
+		t.Errorf("products of equal weight got %d and %d downloads", counts[1], counts[2])
+	}
+}
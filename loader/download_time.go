@@ -0,0 +1,177 @@
+package loader
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultDownloadDays     = 14
+	DefaultDiurnalAmplitude = 0.6  // Evening peak is (1+a)/(1-a) times the night low
+	DefaultWeeklyAmplitude  = 0.15 // Weekdays get 1+a, weekends 1-a
+	DefaultDownloadTimezone = "UTC"
+	diurnalPeakHour         = 20 // Local hour with the most downloads
+)
+
+// DownloadTimeConfig controls when downloads happen
+type DownloadTimeConfig struct {
+	WindowDays       int
+	DiurnalAmplitude float64            // Strength of the day/night cycle, 0 = flat
+	WeeklyAmplitude  float64            // Strength of the weekday/weekend cycle, 0 = flat
+	Trend            float64            // Growth of the download rate across the window, e.g. 0.2 = 20% more at the end
+	Holidays         map[string]float64 // Local date (YYYY-MM-DD) -> rate multiplier
+	Location         *time.Location     // Timezone of the seasonality and of downloaded_at_day_normalized
+}
+
+// ParseDownloadTimeConfig parses the download timing flags
+func ParseDownloadTimeConfig(days int, diurnal, weekly, trend float64, holidays, tz string) (DownloadTimeConfig, error) {
+	cfg := DownloadTimeConfig{
+		WindowDays:       days,
+		DiurnalAmplitude: diurnal,
+		WeeklyAmplitude:  weekly,
+		Trend:            trend,
+		Holidays:         make(map[string]float64),
+	}
+	if days < 1 {
+		return cfg, fmt.Errorf("download window must be >= 1 day, got %d", days)
+	}
+	if diurnal < 0 || diurnal >= 1 {
+		return cfg, fmt.Errorf("diurnal amplitude must be in [0, 1), got %.2f", diurnal)
+	}
+	if weekly < 0 || weekly >= 1 {
+		return cfg, fmt.Errorf("weekly amplitude must be in [0, 1), got %.2f", weekly)
+	}
+	if trend <= -1 {
+		return cfg, fmt.Errorf("download trend must be > -1, got %.2f", trend)
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	cfg.Location = loc
+
+	entries, err := parseRatioList(holidays)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid holidays: %w", err)
+	}
+	for _, e := range entries {
+		if _, err := time.Parse("2006-01-02", e.name); err != nil {
+			return cfg, fmt.Errorf("invalid holiday date %q, expected YYYY-MM-DD", e.name)
+		}
+		if e.value <= 0 {
+			return cfg, fmt.Errorf("holiday multiplier for %s must be > 0, got %.2f", e.name, e.value)
+		}
+		cfg.Holidays[e.name] = e.value
+	}
+	return cfg, nil
+}
+
+func (cfg DownloadTimeConfig) String() string {
+	s := fmt.Sprintf("last %d days in %s, diurnal %.2f, weekly %.2f, trend %+.0f%%",
+		cfg.WindowDays, cfg.Location, cfg.DiurnalAmplitude, cfg.WeeklyAmplitude, cfg.Trend*100)
+	if len(cfg.Holidays) > 0 {
+		dates := make([]string, 0, len(cfg.Holidays))
+		for date, multiplier := range cfg.Holidays {
+			dates = append(dates, fmt.Sprintf("%s x%.1f", date, multiplier))
+		}
+		sort.Strings(dates)
+		s += ", holidays " + strings.Join(dates, ", ")
+	}
+	return s
+}
+
+// downloadClock samples download timestamps from hourly buckets weighted by the seasonality curves
+type downloadClock struct {
+	hours      []time.Time // Bucket starts, each bucket is one hour long and ends before now
+	cumulative []float64
+	location   *time.Location
+}
+
+func newDownloadClock(cfg DownloadTimeConfig, now time.Time) *downloadClock {
+	n := cfg.WindowDays * 24
+	clock := &downloadClock{
+		hours:      make([]time.Time, n),
+		cumulative: make([]float64, n),
+		location:   cfg.Location,
+	}
+
+	growth := math.Log1p(cfg.Trend)
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		start := now.Add(-time.Duration(n-i) * time.Hour)
+		mid := start.Add(30 * time.Minute).In(cfg.Location)
+
+		hour := float64(mid.Hour()) + float64(mid.Minute())/60
+		weight := 1 + cfg.DiurnalAmplitude*math.Cos(2*math.Pi*(hour-diurnalPeakHour)/24)
+		if day := mid.Weekday(); day == time.Saturday || day == time.Sunday {
+			weight *= 1 - cfg.WeeklyAmplitude
+		} else {
+			weight *= 1 + cfg.WeeklyAmplitude
+		}
+		weight *= math.Exp(growth * float64(i) / float64(n))
+		if multiplier, ok := cfg.Holidays[mid.Format("2006-01-02")]; ok {
+			weight *= multiplier
+		}
+
+		sum += weight
+		clock.hours[i] = start
+		clock.cumulative[i] = sum
+	}
+	return clock
+}
+
+// sample returns a download time with microsecond precision, the resolution of timestamptz
+func (c *downloadClock) sample(rng *rand.Rand) time.Time {
+	r := rng.Float64() * c.cumulative[len(c.cumulative)-1]
+	i := sort.SearchFloat64s(c.cumulative, r)
+	if i == len(c.hours) {
+		i--
+	}
+	return c.hours[i].Add(time.Duration(rng.Int63n(int64(time.Hour)))).Truncate(time.Microsecond)
+}
+
+// sampleAfter returns a download time that is not before notBefore, following the same curves
+func (c *downloadClock) sampleAfter(rng *rand.Rand, notBefore time.Time) time.Time {
+	if !notBefore.After(c.hours[0]) {
+		return c.sample(rng)
+	}
+
+	last := len(c.hours) - 1
+	first := sort.Search(len(c.hours), func(i int) bool {
+		return c.hours[i].Add(time.Hour).After(notBefore)
+	})
+	if first > last {
+		// Created within the last few moments, the download can only happen at the very end of the window
+		return c.hours[last].Add(time.Hour).Truncate(time.Microsecond)
+	}
+
+	low := 0.0
+	if first > 0 {
+		low = c.cumulative[first-1]
+	}
+	r := low + rng.Float64()*(c.cumulative[last]-low)
+	i := sort.SearchFloat64s(c.cumulative, r)
+	if i < first {
+		i = first
+	} else if i > last {
+		i = last
+	}
+
+	start := c.hours[i]
+	if start.Before(notBefore) {
+		start = notBefore
+	}
+	end := c.hours[i].Add(time.Hour)
+	return start.Add(time.Duration(rng.Int63n(int64(end.Sub(start)) + 1))).Truncate(time.Microsecond)
+}
+
+// dayNormalized returns the day number of t in the configured timezone (days since 1970-01-01)
+func (c *downloadClock) dayNormalized(t time.Time) int64 {
+	_, offset := t.In(c.location).Zone()
+	return int64(math.Floor(float64(t.Unix()+int64(offset)) / 86400))
+}
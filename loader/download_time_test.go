@@ -0,0 +1,135 @@
+package loader
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseDownloadTimeConfig(t *testing.T) {
+	tests := []struct {
+		days            int
+		diurnal, weekly float64
+		trend           float64
+		holidays, tz    string
+		wantErr         bool
+	}{
+		{DefaultDownloadDays, DefaultDiurnalAmplitude, DefaultWeeklyAmplitude, 0, "", DefaultDownloadTimezone, false},
+		{30, 0, 0, 0.2, "2025-12-25:0.3,2025-11-28:2.5", "America/New_York", false},
+		{0, 0.5, 0.1, 0, "", "UTC", true},
+		{14, 1, 0.1, 0, "", "UTC", true},
+		{14, 0.5, -0.1, 0, "", "UTC", true},
+		{14, 0.5, 0.1, -1, "", "UTC", true},
+		{14, 0.5, 0.1, 0, "", "Mars/Olympus", true},
+		{14, 0.5, 0.1, 0, "25/12/2025:0.3", "UTC", true},
+		{14, 0.5, 0.1, 0, "2025-12-25:0", "UTC", true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseDownloadTimeConfig(tt.days, tt.diurnal, tt.weekly, tt.trend, tt.holidays, tt.tz)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v: got error %v, want error %t", tt, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && tt.holidays != "" && len(cfg.Holidays) != 2 {
+			t.Errorf("%q: parsed holidays %v", tt.holidays, cfg.Holidays)
+		}
+	}
+}
+
+func TestDownloadClockSample(t *testing.T) {
+	cfg, err := ParseDownloadTimeConfig(14, 0.6, 0.15, 0, "", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := newDownloadClock(cfg, now)
+	rng := rand.New(rand.NewSource(1))
+
+	hours := make([]int, 24)
+	for i := 0; i < 50000; i++ {
+		ts := clock.sample(rng)
+		if ts.Before(now.Add(-14*24*time.Hour)) || !ts.Before(now) {
+			t.Fatalf("download at %v outside the 14 day window before %v", ts, now)
+		}
+		if ts.Nanosecond()%1000 != 0 {
+			t.Fatalf("download at %v finer than a microsecond", ts)
+		}
+		hours[ts.Hour()]++
+	}
+
+	// The evening peak sees (1+a)/(1-a) = 4 times the downloads of the night low
+	if ratio := float64(hours[diurnalPeakHour]) / float64(hours[(diurnalPeakHour+12)%24]); ratio < 3 || ratio > 5 {
+		t.Errorf("peak to low hour ratio %.2f, want about 4", ratio)
+	}
+}
+
+func TestDownloadClockHolidays(t *testing.T) {
+	cfg, err := ParseDownloadTimeConfig(7, 0, 0, 0, "2026-02-25:3", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := newDownloadClock(cfg, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	rng := rand.New(rand.NewSource(1))
+
+	holiday := 0
+	const n = 20000
+	for i := 0; i < n; i++ {
+		if clock.sample(rng).Format("2006-01-02") == "2026-02-25" {
+			holiday++
+		}
+	}
+	// One day out of 7 weighs 3: 3/9 of the downloads
+	if share := float64(holiday) / n; share < 0.3 || share > 0.37 {
+		t.Errorf("%.3f of the downloads on the holiday, want 0.33", share)
+	}
+}
+
+func TestDayNormalized(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		location *time.Location
+		ts       time.Time
+		want     int64
+	}{
+		{time.UTC, time.Date(1970, 1, 1, 23, 59, 59, 0, time.UTC), 0},
+		{time.UTC, time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), 1},
+		{tokyo, time.Date(1970, 1, 1, 16, 0, 0, 0, time.UTC), 1}, // Already Jan 2 in Tokyo
+		{time.UTC, time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), -1},
+	}
+	for _, tt := range tests {
+		clock := &downloadClock{location: tt.location}
+		if got := clock.dayNormalized(tt.ts); got != tt.want {
+			t.Errorf("dayNormalized(%v) in %s = %d, want %d", tt.ts, tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestDownloadClockSampleAfter(t *testing.T) {
+	cfg, err := ParseDownloadTimeConfig(14, 0.6, 0.15, 0, "", "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := newDownloadClock(cfg, now)
+	rng := rand.New(rand.NewSource(1))
+
+	for _, notBefore := range []time.Time{
+		now.AddDate(-1, 0, 0),                // Before the window
+		now.Add(-72*time.Hour + time.Minute), // Inside an hour bucket
+		now.Add(-time.Second),                // In the last bucket
+		now.Add(time.Hour),                   // After the window
+	} {
+		for i := 0; i < 1000; i++ {
+			ts := clock.sampleAfter(rng, notBefore)
+			if ts.Before(notBefore) && notBefore.Before(now) {
+				t.Fatalf("download at %v before %v", ts, notBefore)
+			}
+			if ts.After(now) {
+				t.Fatalf("download at %v after the end of the window %v", ts, now)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package loader
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tiny-cds-loader/writer"
+)
+
+// DownloadRow is one generated product_download row
+type DownloadRow struct {
+	ID            int64
+	ProductID     int64
+	DownloadedAt  time.Time
+	DayNormalized int64 // Day of DownloadedAt in the timezone of the download seasonality
+}
+
+// DownloadRows returns the product_download rows of downloads
+func DownloadRows(downloads []DownloadRow) writer.Rows {
+	rows := writer.Rows{
+		Table:   "product_download",
+		Columns: []string{"download_id", "product_id", "downloaded_at", "downloaded_at_day_normalized"},
+		Values:  make([][]interface{}, 0, len(downloads)),
+	}
+	for _, d := range downloads {
+		rows.Values = append(rows.Values, []interface{}{d.ID, d.ProductID, d.DownloadedAt, d.DayNormalized})
+	}
+	return rows
+}
+
+// DownloadGenerator generates downloads of existing products, skewed toward popular, new and promoted ones
+type DownloadGenerator struct {
+	targets *downloadTargets
+}
+
+// NewDownloadGenerator returns a generator spreading downloads over the window of timing ending at now.
+// promoted holds the products with an active promo; rng draws the popularity ranks and trending bursts.
+func NewDownloadGenerator(timing DownloadTimeConfig, popularity DownloadPopularityConfig, products []ProductRef, promoted map[int64]bool, now time.Time, rng *rand.Rand) (*DownloadGenerator, error) {
+	if len(products) == 0 {
+		return nil, fmt.Errorf("no products to download")
+	}
+	return newDownloadGenerator(timing, popularity, newProductSampler(products), promoted, now, rng)
+}
+
+func newDownloadGenerator(timing DownloadTimeConfig, popularity DownloadPopularityConfig, products *idSampler, promoted map[int64]bool, now time.Time, rng *rand.Rand) (*DownloadGenerator, error) {
+	if err := popularity.validate(); err != nil {
+		return nil, err
+	}
+	clock := newDownloadClock(timing, now)
+	return &DownloadGenerator{targets: newDownloadTargets(popularity, products, promoted, clock, now, rng)}, nil
+}
+
+// Generate returns count downloads numbered from startID
+func (g *DownloadGenerator) Generate(rng *rand.Rand, startID int64, count int) []DownloadRow {
+	downloads := make([]DownloadRow, 0, count)
+	for i := 0; i < count; i++ {
+		productID, downloadedAt := g.targets.pick(rng)
+		downloads = append(downloads, DownloadRow{
+			ID:            startID + int64(i),
+			ProductID:     productID,
+			DownloadedAt:  downloadedAt,
+			DayNormalized: g.targets.clock.dayNormalized(downloadedAt),
+		})
+	}
+	return downloads
+}
+
+// ImportDownloads appends downloadCount downloads of the existing products
+func (l *Loader) ImportDownloads(downloadCount int, timing DownloadTimeConfig, popularity DownloadPopularityConfig, sample SampleConfig) error {
+	fmt.Print("\n=== Importing Product Downloads ===\n\n")
+	fmt.Printf("Importing %d downloads using %d workers...\n", downloadCount, Workers)
+	fmt.Printf("Timing: %s\n", timing)
+	fmt.Printf("Popularity: zipf %.2f, recency boost %.1f (half-life %.0f days), promo boost %.1f\n",
+		popularity.Zipf, popularity.RecencyBoost, popularity.RecencyHalfLife, popularity.PromoBoost)
+	fmt.Printf("Trending: %d products, %.0fh bursts, %.1f%% of downloads\n", popularity.Trending, popularity.TrendingHours, popularity.TrendingShare*100)
+
+	fmt.Printf("Sampling: %s\n", sample)
+
+	products, err := loadProductSampler(l.db, sample)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	promoted, err := loadActivePromoProductIDs(l.db, now)
+	if err != nil {
+		return err
+	}
+	generator, err := newDownloadGenerator(timing, popularity, products, promoted, now, l.newRand(-1))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Loaded %d products from database, %d with an active promo\n", products.len(), generator.targets.promoted)
+
+	// Get the starting download ID
+	startDownloadID, err := l.dialect.MaxID(l.db, "product_download", "download_id")
+	if err != nil {
+		return fmt.Errorf("failed to get starting download ID: %w", err)
+	}
+	startDownloadID++ // Start from next available ID
+
+	bar := l.newProgressBar(downloadCount, "Downloads")
+
+	// Create work channel
+	type downloadBatch struct {
+		startDownloadID int64
+		count           int
+	}
+
+	jobs := make(chan downloadBatch, 100)
+	errors := make(chan error, 1000)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	totalInserted := 0
+	var firstError error
+
+	// Error collector goroutine
+	var errorWg sync.WaitGroup
+	errorWg.Add(1)
+	go func() {
+		defer errorWg.Done()
+		for err := range errors {
+			if err != nil && firstError == nil {
+				mu.Lock()
+				if firstError == nil {
+					firstError = err
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	// Start worker goroutines
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			rng := l.newRand(int64(workerID) * 1000)
+
+			for batch := range jobs {
+				downloads := generator.Generate(rng, batch.startDownloadID, batch.count)
+				inserted, err := l.sink.Write(DownloadRows(downloads))
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: %w", workerID, err)
+					continue
+				}
+
+				mu.Lock()
+				totalInserted += int(inserted[0])
+				bar.Add(batch.count)
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	// Send jobs to workers
+	go func() {
+		currentDownloadID := startDownloadID
+		remaining := downloadCount
+		batchSize := 5000 // 5000 downloads per batch
+
+		for remaining > 0 {
+			count := batchSize
+			if remaining < batchSize {
+				count = remaining
+			}
+
+			jobs <- downloadBatch{startDownloadID: currentDownloadID, count: count}
+			currentDownloadID += int64(count)
+			remaining -= count
+		}
+		close(jobs)
+	}()
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(errors)
+
+	// Wait for error collector to finish
+	errorWg.Wait()
+
+	// Check if there was an error
+	if firstError != nil {
+		return firstError
+	}
+
+	fmt.Printf("\n  ✓ Inserted: %d downloads\n", totalInserted)
+	if err := printTopDownloads(l.db); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,502 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	HugeTagID        int64 = 12345 // Tag used by the hugetag mode
+	hotspotBatchSize       = 20000
+)
+
+// HotspotTarget is one hot tag, subcategory or product with its size as a count or a percentage of the catalog
+type HotspotTarget struct {
+	ID      int64
+	Count   int
+	Percent float64 // Wins over Count when set
+}
+
+// size returns the number of rows to generate for a catalog of the given size
+func (t HotspotTarget) size(catalog int) int {
+	if t.Percent > 0 {
+		return int(math.Round(t.Percent / 100 * float64(catalog)))
+	}
+	return t.Count
+}
+
+func (t HotspotTarget) String() string {
+	if t.Percent > 0 {
+		return fmt.Sprintf("%d (%g%%)", t.ID, t.Percent)
+	}
+	return fmt.Sprintf("%d (%d)", t.ID, t.Count)
+}
+
+// HotspotConfig lists the pathological skews to inject
+type HotspotConfig struct {
+	Tags          []HotspotTarget // Tags attached to many products in product_tag
+	Subcategories []HotspotTarget // Subcategories holding many products in product_product_category
+	Products      []HotspotTarget // Products with many rows in product_download
+	Categories    map[int64]bool  // Only target products of these top-level categories, empty = all
+}
+
+// parseHotspotList parses "id:count" or "id:percent%" pairs such as "12345:100000,777:5%"
+func parseHotspotList(s string) ([]HotspotTarget, error) {
+	var targets []HotspotTarget
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, size, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid hot spot %q, expected id:count or id:percent%%", item)
+		}
+
+		var t HotspotTarget
+		var err error
+		if t.ID, err = strconv.ParseInt(strings.TrimSpace(id), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid ID in hot spot %q", item)
+		}
+		size = strings.TrimSpace(size)
+		if percent, isPercent := strings.CutSuffix(size, "%"); isPercent {
+			t.Percent, err = strconv.ParseFloat(percent, 64)
+			if err != nil || t.Percent <= 0 || t.Percent > 100 {
+				return nil, fmt.Errorf("invalid percentage in hot spot %q, expected (0, 100]", item)
+			}
+		} else {
+			t.Count, err = strconv.Atoi(size)
+			if err != nil || t.Count <= 0 {
+				return nil, fmt.Errorf("invalid count in hot spot %q, expected > 0", item)
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// ParseHotspotConfig parses the "-hot-*" flag values
+func ParseHotspotConfig(tags, subcategories, products, categories string) (HotspotConfig, error) {
+	var cfg HotspotConfig
+	var err error
+	if cfg.Tags, err = parseHotspotList(tags); err != nil {
+		return cfg, fmt.Errorf("invalid hot tags: %w", err)
+	}
+	if cfg.Subcategories, err = parseHotspotList(subcategories); err != nil {
+		return cfg, fmt.Errorf("invalid hot subcategories: %w", err)
+	}
+	if cfg.Products, err = parseHotspotList(products); err != nil {
+		return cfg, fmt.Errorf("invalid hot products: %w", err)
+	}
+
+	cfg.Categories = make(map[int64]bool)
+	for _, item := range strings.Split(categories, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid hot spot category %q", item)
+		}
+		cfg.Categories[id] = true
+	}
+	return cfg, nil
+}
+
+func (cfg HotspotConfig) empty() bool {
+	return len(cfg.Tags) == 0 && len(cfg.Subcategories) == 0 && len(cfg.Products) == 0
+}
+
+// ImportHotspots injects every configured hot spot, one after the other
+func (l *Loader) ImportHotspots(cfg HotspotConfig, sample SampleConfig, timing DownloadTimeConfig) error {
+	fmt.Print("\n=== Injecting Hot Spots ===\n\n")
+
+	if cfg.empty() {
+		return fmt.Errorf("no hot spots configured - use -hot-tags, -hot-subcategories or -hot-products")
+	}
+	fmt.Printf("Sampling: %s\n", sample)
+
+	products, err := loadProductSampler(l.db, sample)
+	if err != nil {
+		return err
+	}
+
+	// Percentages are of the whole catalog, not of the TABLESAMPLE
+	catalog := int(math.Round(float64(products.len()) * 100 / sample.Percent))
+	if len(cfg.Categories) > 0 {
+		// Products only carry their top-level category, so subcategories cannot restrict them
+		tree, err := loadCategoryTree(l.db)
+		if err != nil {
+			return err
+		}
+		for _, id := range sortedIDs(cfg.Categories) {
+			if _, ok := tree.nodes[id]; !ok {
+				return fmt.Errorf("hot spot category %d does not exist", id)
+			}
+			if root := tree.root(id); root != id {
+				return fmt.Errorf("hot spot category %d is a subcategory, list its top-level category %d instead", id, root)
+			}
+		}
+
+		catalog = 0
+		for i := range products.ids {
+			if cfg.Categories[products.categories[i]] {
+				catalog++
+			}
+		}
+		catalog = int(math.Round(float64(catalog) * 100 / sample.Percent))
+		products.reweight(func(i int) float64 {
+			if cfg.Categories[products.categories[i]] {
+				return 1
+			}
+			return 0
+		})
+		if catalog == 0 {
+			return fmt.Errorf("no products found in the hot spot categories")
+		}
+		fmt.Printf("Restricted to %d products of categories %s\n", catalog, joinIDs(cfg.Categories))
+	}
+	fmt.Printf("Loaded %d products from database\n", products.len())
+
+	rng := l.newRand(-1)
+
+	for _, t := range cfg.Tags {
+		if err := l.injectHotTag(t, products, catalog, rng); err != nil {
+			return err
+		}
+	}
+	for _, t := range cfg.Subcategories {
+		if err := l.injectHotSubcategory(t, products, cfg.Categories, sample.Percent, rng); err != nil {
+			return err
+		}
+	}
+	if len(cfg.Products) > 0 {
+		clock := newDownloadClock(timing, time.Now())
+		for _, t := range cfg.Products {
+			if err := l.injectHotProduct(t, catalog, clock); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// sortedIDs returns the IDs of a set in ascending order, so output doesn't change from run to run
+func sortedIDs(ids map[int64]bool) []int64 {
+	sorted := make([]int64, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// joinIDs prints a set of IDs
+func joinIDs(ids map[int64]bool) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range sortedIDs(ids) {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// loadLinkedProducts returns the products already linked to id by the given query
+func loadLinkedProducts(db *sql.DB, query string, id int64) (map[int64]bool, error) {
+	rows, err := db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	linked := make(map[int64]bool)
+	for rows.Next() {
+		var productID int64
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+		linked[productID] = true
+	}
+	return linked, rows.Err()
+}
+
+// AttachHotTag attaches a tag to count generated products that don't carry it yet, picked like the hot tags
+// of the hotspot and hugetag modes, and returns how many of the products carry it in the end
+func AttachHotTag(rng *rand.Rand, products []ProductRow, tagID int64, count int) (int, error) {
+	refs := make([]ProductRef, len(products))
+	positions := make(map[int64]int, len(products))
+	linked := make(map[int64]bool)
+	for i, p := range products {
+		refs[i] = ProductRef{ID: p.ID, CategoryID: p.CategoryID, CreatedAt: p.CreatedAt}
+		positions[p.ID] = i
+		for _, id := range p.Tags {
+			if id == tagID {
+				linked[p.ID] = true
+			}
+		}
+	}
+
+	targets, err := SampleProducts(rng, refs, count, linked)
+	if err != nil {
+		return 0, fmt.Errorf("hot tag %d: %w", tagID, err)
+	}
+	for _, target := range targets {
+		i := positions[target.ID]
+		products[i].Tags = append(products[i].Tags, tagID)
+	}
+	return len(linked) + len(targets), nil
+}
+
+// injectHotTag attaches a tag to distinct products that don't have it yet
+func (l *Loader) injectHotTag(t HotspotTarget, products *idSampler, catalog int, rng *rand.Rand) error {
+	var exists bool
+	if err := l.db.QueryRow("SELECT EXISTS (SELECT 1 FROM tag WHERE tag_id = $1)", t.ID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up tag %d: %w", t.ID, err)
+	}
+	if !exists {
+		return fmt.Errorf("tag %d does not exist - please import tags first", t.ID)
+	}
+
+	linked, err := loadLinkedProducts(l.db, "SELECT product_id FROM product_tag WHERE tag_id = $1", t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load products of tag %d: %w", t.ID, err)
+	}
+
+	count := t.size(catalog)
+	fmt.Printf("\nHot tag %d: %d products (%d already tagged)\n", t.ID, count, len(linked))
+	targets, err := products.sampleDistinct(rng, count, linked)
+	if err != nil {
+		return fmt.Errorf("hot tag %d: %w", t.ID, err)
+	}
+
+	inserted, err := l.runHotspotBatches(fmt.Sprintf("Tag %d", t.ID), targets, func(tx *sql.Tx, batch []int) (int64, error) {
+		productIDs := make([]int64, len(batch))
+		createdAts := make([]string, len(batch))
+		for i, j := range batch {
+			productIDs[i] = products.ids[j]
+			createdAts[i] = products.createdAt[j].Format(time.RFC3339Nano)
+		}
+		result, err := tx.Exec(`
+			INSERT INTO product_tag (product_id, tag_id, product_created_at)
+			SELECT u.product_id, $2::bigint, u.created_at
+			FROM unnest($1::bigint[], $3::timestamptz[]) AS u(product_id, created_at)
+			ON CONFLICT DO NOTHING
+		`, pq.Array(productIDs), t.ID, pq.Array(createdAts))
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert tag relations: %w", err)
+		}
+		return result.RowsAffected()
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n  ✓ Inserted: %d tag relations\n", inserted)
+	return nil
+}
+
+// injectHotSubcategory puts distinct products that aren't in it yet into a subcategory. Like the products
+// mode, only products of the subcategory's top-level category are put into it, and percentages refer to them.
+func (l *Loader) injectHotSubcategory(t HotspotTarget, products *idSampler, categories map[int64]bool, samplePercent float64, rng *rand.Rand) error {
+	var exists bool
+	if err := l.db.QueryRow("SELECT EXISTS (SELECT 1 FROM category WHERE category_id = $1)", t.ID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up category %d: %w", t.ID, err)
+	}
+	if !exists {
+		return fmt.Errorf("category %d does not exist - please import categories and subcategories first", t.ID)
+	}
+
+	linked, err := loadLinkedProducts(l.db, "SELECT product_id FROM product_product_category WHERE category_id = $1", t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load products of category %d: %w", t.ID, err)
+	}
+
+	tree, err := loadCategoryTree(l.db)
+	if err != nil {
+		return err
+	}
+	root := tree.root(t.ID)
+	if len(categories) > 0 && !categories[root] {
+		return fmt.Errorf("hot subcategory %d belongs to category %d, which the hot spot categories exclude", t.ID, root)
+	}
+	eligible := products.filter(func(i int) bool { return products.categories[i] == root })
+
+	// Percentages are of the products of the top-level category, not of the TABLESAMPLE
+	count := t.size(int(math.Round(float64(eligible.len()) * 100 / samplePercent)))
+	fmt.Printf("\nHot subcategory %d: %d products of category %d (%d already in it)\n", t.ID, count, root, len(linked))
+	targets, err := eligible.sampleDistinct(rng, count, linked)
+	if err != nil {
+		return fmt.Errorf("hot subcategory %d: %w", t.ID, err)
+	}
+
+	inserted, err := l.runHotspotBatches(fmt.Sprintf("Subcategory %d", t.ID), targets, func(tx *sql.Tx, batch []int) (int64, error) {
+		productIDs := make([]int64, len(batch))
+		for i, j := range batch {
+			productIDs[i] = eligible.ids[j]
+		}
+		result, err := tx.Exec(`
+			INSERT INTO product_product_category (product_id, category_id)
+			SELECT u.product_id, $2::bigint
+			FROM unnest($1::bigint[]) AS u(product_id)
+			ON CONFLICT DO NOTHING
+		`, pq.Array(productIDs), t.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert product categories: %w", err)
+		}
+		return result.RowsAffected()
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n  ✓ Inserted: %d product categories\n", inserted)
+	return nil
+}
+
+// injectHotProduct adds downloads of one product, spread over the download window after its creation
+func (l *Loader) injectHotProduct(t HotspotTarget, catalog int, clock *downloadClock) error {
+	var createdAt time.Time
+	err := l.db.QueryRow("SELECT created_at FROM product WHERE product_id = $1", t.ID).Scan(&createdAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("product %d does not exist", t.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up product %d: %w", t.ID, err)
+	}
+
+	var startDownloadID int64
+	err = l.db.QueryRow("SELECT COALESCE(MAX(download_id), 0) FROM product_download").Scan(&startDownloadID)
+	if err != nil {
+		return fmt.Errorf("failed to get starting download ID: %w", err)
+	}
+	startDownloadID++
+
+	count := t.size(catalog)
+	fmt.Printf("\nHot product %d: %d downloads\n", t.ID, count)
+
+	// Batches only need their offset in the download ID sequence
+	offsets := make([]int, count)
+	for i := range offsets {
+		offsets[i] = i
+	}
+
+	inserted, err := l.runHotspotBatches(fmt.Sprintf("Product %d", t.ID), offsets, func(tx *sql.Tx, batch []int) (int64, error) {
+		// Seeded by position so the downloads don't depend on which worker inserts the batch
+		rng := l.newRand(t.ID<<32 + int64(batch[0]))
+		downloadIDs := make([]int64, len(batch))
+		downloadedAts := make([]string, len(batch))
+		days := make([]int64, len(batch))
+		for i, offset := range batch {
+			downloadedAt := clock.sampleAfter(rng, createdAt)
+			downloadIDs[i] = startDownloadID + int64(offset)
+			downloadedAts[i] = downloadedAt.Format(time.RFC3339Nano)
+			days[i] = clock.dayNormalized(downloadedAt)
+		}
+		result, err := tx.Exec(`
+			INSERT INTO product_download (download_id, product_id, downloaded_at, downloaded_at_day_normalized)
+			SELECT u.download_id, $2::bigint, u.downloaded_at, u.day
+			FROM unnest($1::bigint[], $3::timestamptz[], $4::bigint[]) AS u(download_id, downloaded_at, day)
+		`, pq.Array(downloadIDs), t.ID, pq.Array(downloadedAts), pq.Array(days))
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert downloads: %w", err)
+		}
+		return result.RowsAffected()
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n  ✓ Inserted: %d downloads\n", inserted)
+	return nil
+}
+
+// runHotspotBatches splits items into batches and inserts them in parallel, one transaction per batch
+func (l *Loader) runHotspotBatches(description string, items []int, insert func(tx *sql.Tx, batch []int) (int64, error)) (int64, error) {
+	bar := l.newProgressBar(len(items), description)
+
+	jobs := make(chan []int, 100)
+	errors := make(chan error, 1000)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var totalInserted int64
+	var firstError error
+
+	// Error collector goroutine
+	var errorWg sync.WaitGroup
+	errorWg.Add(1)
+	go func() {
+		defer errorWg.Done()
+		for err := range errors {
+			mu.Lock()
+			if firstError == nil {
+				firstError = err
+			}
+			mu.Unlock()
+		}
+	}()
+
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			for batch := range jobs {
+				inserted, err := insertHotspotBatch(l.db, batch, insert)
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: %w", workerID, err)
+					continue
+				}
+
+				mu.Lock()
+				totalInserted += inserted
+				bar.Add(len(batch))
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	go func() {
+		for start := 0; start < len(items); start += hotspotBatchSize {
+			end := start + hotspotBatchSize
+			if end > len(items) {
+				end = len(items)
+			}
+			jobs <- items[start:end]
+		}
+		close(jobs)
+	}()
+
+	wg.Wait()
+	close(errors)
+	errorWg.Wait()
+
+	if firstError != nil {
+		return 0, firstError
+	}
+	return totalInserted, nil
+}
+
+// insertHotspotBatch inserts one batch in its own transaction
+func insertHotspotBatch(db *sql.DB, batch []int, insert func(tx *sql.Tx, batch []int) (int64, error)) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inserted, err := insert(tx, batch)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return inserted, nil
+}
@@ -0,0 +1,108 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHotspotList(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []HotspotTarget
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"12345:100000", []HotspotTarget{{ID: 12345, Count: 100000}}, false},
+		{"12345:100000, 777:5%", []HotspotTarget{{ID: 12345, Count: 100000}, {ID: 777, Percent: 5}}, false},
+		{" 1 : 0.5% ,,", []HotspotTarget{{ID: 1, Percent: 0.5}}, false},
+		{"12345", nil, true},
+		{"tag:10", nil, true},
+		{"1:0", nil, true},
+		{"1:-5", nil, true},
+		{"1:0%", nil, true},
+		{"1:101%", nil, true},
+		{"1:ten", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseHotspotList(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHotspotList(%q): got error %v, want error %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseHotspotList(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseHotspotConfig(t *testing.T) {
+	tests := []struct {
+		name                                      string
+		tags, subcategories, products, categories string
+		wantCategories                            map[int64]bool
+		wantEmpty                                 bool
+		wantErr                                   bool
+	}{
+		{"nothing", "", "", "", "", map[int64]bool{}, true, false},
+		{"tags only", "12345:1000", "", "", "", map[int64]bool{}, false, false},
+		{"restricted", "", "10042:5%", "1:1000", "23, 546", map[int64]bool{23: true, 546: true}, false, false},
+		{"bad tags", "x", "", "", "", nil, false, true},
+		{"bad subcategories", "", "1:0", "", "", nil, false, true},
+		{"bad products", "", "", "1:200%", "", nil, false, true},
+		{"bad category", "1:10", "", "", "fonts", nil, false, true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseHotspotConfig(tt.tags, tt.subcategories, tt.products, tt.categories)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if cfg.empty() != tt.wantEmpty {
+			t.Errorf("%s: empty() = %t, want %t", tt.name, cfg.empty(), tt.wantEmpty)
+		}
+		if !reflect.DeepEqual(cfg.Categories, tt.wantCategories) {
+			t.Errorf("%s: categories = %v, want %v", tt.name, cfg.Categories, tt.wantCategories)
+		}
+	}
+}
+
+func TestHotspotTargetSize(t *testing.T) {
+	tests := []struct {
+		target  HotspotTarget
+		catalog int
+		want    int
+		str     string
+	}{
+		{HotspotTarget{ID: 1, Count: 500}, 100000, 500, "1 (500)"},
+		{HotspotTarget{ID: 2, Percent: 5}, 100000, 5000, "2 (5%)"},
+		{HotspotTarget{ID: 3, Percent: 0.5}, 999, 5, "3 (0.5%)"},
+		{HotspotTarget{ID: 4, Percent: 100}, 0, 0, "4 (100%)"},
+	}
+	for _, tt := range tests {
+		if got := tt.target.size(tt.catalog); got != tt.want {
+			t.Errorf("%v.size(%d) = %d, want %d", tt.target, tt.catalog, got, tt.want)
+		}
+		if got := tt.target.String(); got != tt.str {
+			t.Errorf("String() = %q, want %q", got, tt.str)
+		}
+	}
+}
+
+func TestJoinIDs(t *testing.T) {
+	tests := []struct {
+		ids  map[int64]bool
+		want string
+	}{
+		{map[int64]bool{}, ""},
+		{map[int64]bool{23: true}, "23"},
+		{map[int64]bool{2248: true, 23: true, 546: true, 1850: true}, "23, 546, 1850, 2248"},
+	}
+	for _, tt := range tests {
+		if got := joinIDs(tt.ids); got != tt.want {
+			t.Errorf("joinIDs(%v) = %q, want %q", tt.ids, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package loader
+
+import (
+	"container/heap"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SampleConfig controls how existing product IDs are loaded for the modes referencing products
+type SampleConfig struct {
+	Percent         float64           // Load a TABLESAMPLE SYSTEM sample of this percentage of the products, 100 = all
+	CategoryWeights map[int64]float64 // Weight multiplier per top-level category, unlisted categories weigh 1
+	PublishedOnly   bool              // Only load products with product_status 'published'
+}
+
+// ParseSampleConfig parses the "-sample-percent" and "-category-weights" flag values
+func ParseSampleConfig(percent float64, categoryWeights string) (SampleConfig, error) {
+	cfg := SampleConfig{Percent: percent, CategoryWeights: make(map[int64]float64)}
+	if percent <= 0 || percent > 100 {
+		return cfg, fmt.Errorf("sample percent must be in (0, 100], got %.2f", percent)
+	}
+
+	entries, err := parseRatioList(categoryWeights)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid category weights: %w", err)
+	}
+	for _, e := range entries {
+		categoryID, err := strconv.ParseInt(e.name, 10, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid category %q in category weights", e.name)
+		}
+		if e.value < 0 {
+			return cfg, fmt.Errorf("weight for category %d must be >= 0, got %.2f", categoryID, e.value)
+		}
+		cfg.CategoryWeights[categoryID] = e.value
+	}
+	return cfg, nil
+}
+
+func (cfg SampleConfig) String() string {
+	s := "all products"
+	if cfg.Percent < 100 {
+		s = fmt.Sprintf("%.2f%% TABLESAMPLE of the products", cfg.Percent)
+	}
+	if cfg.PublishedOnly {
+		s += ", published only"
+	}
+	if len(cfg.CategoryWeights) > 0 {
+		weights := make([]string, 0, len(cfg.CategoryWeights))
+		for categoryID, weight := range cfg.CategoryWeights {
+			weights = append(weights, fmt.Sprintf("%d x%.2f", categoryID, weight))
+		}
+		sort.Strings(weights)
+		s += ", category weights " + strings.Join(weights, ", ")
+	}
+	return s
+}
+
+// idSampler draws IDs from a set loaded from the database, so every generated reference exists
+type idSampler struct {
+	ids        []int64
+	categories []int64     // Product category_id, aligned with ids (product samplers only)
+	createdAt  []time.Time // Product created_at, aligned with ids (product samplers only)
+	cumulative []float64   // nil picks uniformly
+	maxID      int64       // Set instead of ids for the dense range 1..maxID, which is picked from uniformly
+}
+
+// ProductRef is an existing product referenced by generated promos and downloads
+type ProductRef struct {
+	ID         int64
+	CategoryID int64 // Top-level category
+	CreatedAt  time.Time
+}
+
+// newProductSampler draws uniformly from products
+func newProductSampler(products []ProductRef) *idSampler {
+	s := &idSampler{}
+	for _, p := range products {
+		s.ids = append(s.ids, p.ID)
+		s.categories = append(s.categories, p.CategoryID)
+		s.createdAt = append(s.createdAt, p.CreatedAt)
+	}
+	return s
+}
+
+// SampleProducts picks n distinct products that are not excluded, uniformly and without replacement, the
+// way the promos and hotspot modes choose their targets
+func SampleProducts(rng *rand.Rand, products []ProductRef, n int, exclude map[int64]bool) ([]ProductRef, error) {
+	s := newProductSampler(products)
+	targets, err := s.sampleDistinct(rng, n, exclude)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]ProductRef, len(targets))
+	for i, target := range targets {
+		refs[i] = s.ref(target)
+	}
+	return refs, nil
+}
+
+// ref returns the product at position i of a product sampler
+func (s *idSampler) ref(i int) ProductRef {
+	return ProductRef{ID: s.ids[i], CategoryID: s.categories[i], CreatedAt: s.createdAt[i]}
+}
+
+// loadProductSampler loads the existing products, or a TABLESAMPLE of them, with their category and created_at
+func loadProductSampler(db *sql.DB, cfg SampleConfig) (*idSampler, error) {
+	query := "SELECT product_id, category_id, created_at FROM product"
+	if cfg.Percent < 100 {
+		query += fmt.Sprintf(" TABLESAMPLE SYSTEM (%g)", cfg.Percent)
+	}
+	if cfg.PublishedOnly {
+		query += " WHERE product_status = 'published'"
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load product IDs: %w", err)
+	}
+	defer rows.Close()
+
+	s := &idSampler{}
+	for rows.Next() {
+		var id, categoryID int64
+		var createdAt time.Time
+		if err := rows.Scan(&id, &categoryID, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan product ID: %w", err)
+		}
+		s.ids = append(s.ids, id)
+		s.categories = append(s.categories, categoryID)
+		s.createdAt = append(s.createdAt, createdAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.ids) == 0 && cfg.PublishedOnly {
+		return nil, fmt.Errorf("no published products found in database")
+	}
+	if len(s.ids) == 0 {
+		return nil, fmt.Errorf("no products found in database - please import products first")
+	}
+
+	if len(cfg.CategoryWeights) > 0 {
+		s.reweight(func(i int) float64 {
+			if weight, ok := cfg.CategoryWeights[s.categories[i]]; ok {
+				return weight
+			}
+			return 1
+		})
+		if s.cumulative[len(s.cumulative)-1] <= 0 {
+			return nil, fmt.Errorf("category weights exclude every loaded product")
+		}
+	}
+	return s, nil
+}
+
+// loadTagSampler samples the existing tag IDs, so tags must be imported first. The tags mode writes the dense
+// range 1..N, which is sampled from its bounds alone; only a tag table with gaps has its IDs loaded, 8 bytes
+// per tag (about 100 MB for 12 million tags).
+func loadTagSampler(db *sql.DB) (*idSampler, error) {
+	var count, minID, maxID int64
+	err := db.QueryRow("SELECT COUNT(*), COALESCE(MIN(tag_id), 0), COALESCE(MAX(tag_id), 0) FROM tag").Scan(&count, &minID, &maxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("no tags found in database - please import tags first")
+	}
+	if minID == 1 && maxID == count {
+		return &idSampler{maxID: maxID}, nil
+	}
+
+	ids, err := loadTagIDs(db)
+	if err != nil {
+		return nil, err
+	}
+	return &idSampler{ids: ids}, nil
+}
+
+// reweight multiplies the weight of every ID by weight(i)
+func (s *idSampler) reweight(weight func(i int) float64) {
+	cumulative := make([]float64, len(s.ids))
+	sum, prev := 0.0, 0.0
+	for i := range s.ids {
+		w := 1.0
+		if s.cumulative != nil {
+			w = s.cumulative[i] - prev
+			prev = s.cumulative[i]
+		}
+		sum += w * weight(i)
+		cumulative[i] = sum
+	}
+	s.cumulative = cumulative
+}
+
+// filter returns a sampler of the IDs at the positions keep accepts, with their weights
+func (s *idSampler) filter(keep func(i int) bool) *idSampler {
+	f := &idSampler{}
+	sum, prev := 0.0, 0.0
+	for i, id := range s.ids {
+		w := 1.0
+		if s.cumulative != nil {
+			w = s.cumulative[i] - prev
+			prev = s.cumulative[i]
+		}
+		if !keep(i) {
+			continue
+		}
+		f.ids = append(f.ids, id)
+		if s.categories != nil {
+			f.categories = append(f.categories, s.categories[i])
+		}
+		if s.createdAt != nil {
+			f.createdAt = append(f.createdAt, s.createdAt[i])
+		}
+		if s.cumulative != nil {
+			sum += w
+			f.cumulative = append(f.cumulative, sum)
+		}
+	}
+	return f
+}
+
+// index draws the position of an ID, in proportion to its weight
+func (s *idSampler) index(rng *rand.Rand) int {
+	if s.cumulative == nil {
+		return rng.Intn(len(s.ids))
+	}
+	// Searching for the first cumulative weight above r never lands on a zero-weight ID
+	r := rng.Float64() * s.cumulative[len(s.cumulative)-1]
+	return sort.Search(len(s.cumulative), func(i int) bool { return s.cumulative[i] > r })
+}
+
+// sampleDistinct draws the positions of n distinct IDs without replacement, in proportion to their weights and skipping
+// the excluded ones. It keeps the n largest Efraimidis-Spirakis keys log(u)/weight in a min-heap.
+func (s *idSampler) sampleDistinct(rng *rand.Rand, n int, exclude map[int64]bool) ([]int, error) {
+	keys := &sampleHeap{}
+	prev := 0.0
+	for i, id := range s.ids {
+		weight := 1.0
+		if s.cumulative != nil {
+			weight = s.cumulative[i] - prev
+			prev = s.cumulative[i]
+		}
+		if weight <= 0 || exclude[id] {
+			continue
+		}
+
+		key := math.Log(1-rng.Float64()) / weight
+		if keys.Len() < n {
+			heap.Push(keys, sampleKey{index: i, key: key})
+		} else if n > 0 && key > (*keys)[0].key {
+			(*keys)[0] = sampleKey{index: i, key: key}
+			heap.Fix(keys, 0)
+		}
+	}
+	if keys.Len() < n {
+		return nil, fmt.Errorf("only %d eligible IDs available, %d requested", keys.Len(), n)
+	}
+
+	indexes := make([]int, keys.Len())
+	for i, k := range *keys {
+		indexes[i] = k.index
+	}
+	rng.Shuffle(len(indexes), func(i, j int) { indexes[i], indexes[j] = indexes[j], indexes[i] })
+	return indexes, nil
+}
+
+type sampleKey struct {
+	index int
+	key   float64
+}
+
+// sampleHeap is a min-heap of sample keys
+type sampleHeap []sampleKey
+
+func (h sampleHeap) Len() int            { return len(h) }
+func (h sampleHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h sampleHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *sampleHeap) Push(x interface{}) { *h = append(*h, x.(sampleKey)) }
+func (h *sampleHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// pick draws one ID
+func (s *idSampler) pick(rng *rand.Rand) int64 {
+	if s.ids == nil && s.maxID > 0 {
+		return rng.Int63n(s.maxID) + 1
+	}
+	return s.ids[s.index(rng)]
+}
+
+// len returns the number of IDs to pick from
+func (s *idSampler) len() int {
+	if s.ids == nil {
+		return int(s.maxID)
+	}
+	return len(s.ids)
+}
@@ -0,0 +1,182 @@
+package loader
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParseSampleConfig(t *testing.T) {
+	tests := []struct {
+		percent float64
+		weights string
+		want    map[int64]float64
+		wantErr bool
+	}{
+		{100, "", map[int64]float64{}, false},
+		{0.5, "23:2,546:0", map[int64]float64{23: 2, 546: 0}, false},
+		{0, "", nil, true},
+		{101, "", nil, true},
+		{100, "fonts:2", nil, true},
+		{100, "23:-1", nil, true},
+		{100, "23", nil, true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseSampleConfig(tt.percent, tt.weights)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSampleConfig(%g, %q): got error %v, want error %t", tt.percent, tt.weights, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(cfg.CategoryWeights, tt.want) {
+			t.Errorf("ParseSampleConfig(%g, %q) weights = %v, want %v", tt.percent, tt.weights, cfg.CategoryWeights, tt.want)
+		}
+	}
+}
+
+func TestIDSamplerPick(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight func(i int) float64 // nil keeps the sampler uniform
+		want   []float64           // Expected share of each ID
+	}{
+		{"uniform", nil, []float64{0.25, 0.25, 0.25, 0.25}},
+		{"weighted", func(i int) float64 { return float64(i + 1) }, []float64{0.1, 0.2, 0.3, 0.4}},
+		{"zero weights", func(i int) float64 { return float64(i % 2) }, []float64{0, 0.5, 0, 0.5}},
+	}
+	for _, tt := range tests {
+		s := &idSampler{ids: []int64{10, 20, 30, 40}}
+		if tt.weight != nil {
+			s.reweight(tt.weight)
+		}
+
+		rng := rand.New(rand.NewSource(1))
+		counts := make(map[int64]int)
+		const n = 40000
+		for i := 0; i < n; i++ {
+			counts[s.pick(rng)]++
+		}
+		for i, id := range s.ids {
+			share := float64(counts[id]) / n
+			if tt.want[i] == 0 && counts[id] > 0 {
+				t.Errorf("%s: zero-weight ID %d picked %d times", tt.name, id, counts[id])
+			} else if share < tt.want[i]-0.02 || share > tt.want[i]+0.02 {
+				t.Errorf("%s: ID %d got %.3f of the picks, want %.2f", tt.name, id, share, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestIDSamplerReweightMultiplies(t *testing.T) {
+	s := &idSampler{ids: []int64{1, 2, 3}}
+	s.reweight(func(i int) float64 { return float64(i + 1) })
+	s.reweight(func(i int) float64 { return 2 })
+	if want := []float64{2, 6, 12}; !reflect.DeepEqual(s.cumulative, want) {
+		t.Errorf("cumulative weights = %v, want %v", s.cumulative, want)
+	}
+}
+
+func TestIDSamplerSampleDistinct(t *testing.T) {
+	tests := []struct {
+		name    string
+		weight  func(i int) float64
+		n       int
+		exclude map[int64]bool
+		wantErr bool
+	}{
+		{"all", nil, 6, nil, false},
+		{"some", nil, 3, nil, false},
+		{"none", nil, 0, nil, false},
+		{"excluded", nil, 4, map[int64]bool{1: true, 2: true}, false},
+		{"too many after exclusion", nil, 5, map[int64]bool{1: true, 2: true}, true},
+		{"zero weights skipped", func(i int) float64 { return float64(i % 2) }, 3, nil, false},
+		{"too many with zero weights", func(i int) float64 { return float64(i % 2) }, 4, nil, true},
+	}
+	for _, tt := range tests {
+		s := &idSampler{ids: []int64{1, 2, 3, 4, 5, 6}}
+		if tt.weight != nil {
+			s.reweight(tt.weight)
+		}
+		indexes, err := s.sampleDistinct(rand.New(rand.NewSource(1)), tt.n, tt.exclude)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if len(indexes) != tt.n {
+			t.Errorf("%s: got %d IDs, want %d", tt.name, len(indexes), tt.n)
+		}
+		seen := make(map[int64]bool)
+		for _, i := range indexes {
+			id := s.ids[i]
+			if seen[id] {
+				t.Errorf("%s: ID %d drawn twice", tt.name, id)
+			}
+			if tt.exclude[id] {
+				t.Errorf("%s: excluded ID %d drawn", tt.name, id)
+			}
+			if tt.weight != nil && tt.weight(int(id-1)) == 0 {
+				t.Errorf("%s: zero-weight ID %d drawn", tt.name, id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestIDSamplerSampleDistinctFollowsWeights(t *testing.T) {
+	s := &idSampler{ids: []int64{1, 2, 3, 4}}
+	s.reweight(func(i int) float64 { return float64(i*i + 1) })
+
+	rng := rand.New(rand.NewSource(1))
+	counts := make(map[int64]int)
+	for i := 0; i < 5000; i++ {
+		indexes, err := s.sampleDistinct(rng, 1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[s.ids[indexes[0]]]++
+	}
+	if !(counts[1] < counts[2] && counts[2] < counts[3] && counts[3] < counts[4]) {
+		t.Errorf("single draws %v do not follow the weights 1, 2, 5, 10", counts)
+	}
+}
+
+func TestIDSamplerDenseRange(t *testing.T) {
+	s := &idSampler{maxID: 5}
+	if s.len() != 5 {
+		t.Errorf("len() = %d, want 5", s.len())
+	}
+	rng := rand.New(rand.NewSource(1))
+	seen := make(map[int64]bool)
+	for i := 0; i < 1000; i++ {
+		id := s.pick(rng)
+		if id < 1 || id > 5 {
+			t.Fatalf("pick() = %d, want 1..5", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("picked %d distinct IDs of 5", len(seen))
+	}
+}
+
+func TestIDSamplerFilter(t *testing.T) {
+	s := &idSampler{ids: []int64{1, 2, 3, 4}, categories: []int64{23, 546, 23, 735}}
+	s.reweight(func(i int) float64 { return float64(i + 1) })
+
+	f := s.filter(func(i int) bool { return s.categories[i] == 23 })
+	if want := []int64{1, 3}; !reflect.DeepEqual(f.ids, want) {
+		t.Errorf("filtered IDs = %v, want %v", f.ids, want)
+	}
+	if want := []int64{23, 23}; !reflect.DeepEqual(f.categories, want) {
+		t.Errorf("filtered categories = %v, want %v", f.categories, want)
+	}
+	if want := []float64{1, 4}; !reflect.DeepEqual(f.cumulative, want) {
+		t.Errorf("filtered cumulative weights = %v, want %v (the weights 1 and 3 are kept)", f.cumulative, want)
+	}
+
+	if none := s.filter(func(int) bool { return false }); none.len() != 0 {
+		t.Errorf("filtering out everything left %d IDs", none.len())
+	}
+}
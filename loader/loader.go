@@ -0,0 +1,98 @@
+// Package loader generates a realistic CDS catalog and loads it into a database. The generators turn a
+// config into rows, the Loader runs them with parallel workers and writes their rows through a sink.
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+
+	"tiny-cds-loader/writer"
+)
+
+const (
+	DefaultTagCount   = 12000000 // 12 million tags as per specs
+	batchSize         = 10000    // Insert tags in batches (limited by PostgreSQL's 65535 parameter limit)
+	Workers           = 40       // Number of parallel workers (increased for better throughput)
+	productBatchSize  = 4000     // Products per batch (4000 * 16 params = 64,000 < 65,535 limit)
+	avgTagsPerProduct = 25       // Average tags per product
+)
+
+// Options configure a Loader
+type Options struct {
+	Seed  int64 // Base of every random generator, 0 derives it from the current time
+	Quiet bool  // Hide the progress bars, e.g. when several schemas are loaded at once
+}
+
+// Loader runs the import modes against one database: the generators get the rows they reference from
+// db and their output is written through a DBSink, batch by batch
+type Loader struct {
+	db      *sql.DB
+	dialect writer.Dialect
+	sink    writer.Sink
+	seed    int64
+	quiet   bool
+}
+
+// New returns a loader for db, whose connections must already use the schema to load
+func New(db *sql.DB, dialect writer.Dialect, opts Options) *Loader {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Loader{db: db, dialect: dialect, sink: writer.NewDBSink(db, dialect), seed: seed, quiet: opts.Quiet}
+}
+
+// newRand returns a generator derived from the loader seed; salt separates workers and phases
+func (l *Loader) newRand(salt int64) *rand.Rand {
+	return rand.New(rand.NewSource(l.seed + salt))
+}
+
+// newProgressBar builds a progress bar with the theme shared by every import mode
+func (l *Loader) newProgressBar(total int, description string) *progressbar.ProgressBar {
+	return progressbar.NewOptions(total,
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWidth(40),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "=",
+			SaucerHead:    ">",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}),
+		progressbar.OptionSetVisibility(!l.quiet),
+	)
+}
+
+// ratioEntry is one "name:value" pair of a list flag such as -locales
+type ratioEntry struct {
+	name  string
+	value float64
+}
+
+// parseRatioList parses "name:value,name:value" pairs, preserving their order
+func parseRatioList(s string) ([]ratioEntry, error) {
+	var entries []ratioEntry
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q, expected name:value", item)
+		}
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in %q: %w", item, err)
+		}
+		entries = append(entries, ratioEntry{name: strings.TrimSpace(name), value: ratio})
+	}
+	return entries, nil
+}
@@ -0,0 +1,31 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRatioList(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []ratioEntry
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"en:1.0", []ratioEntry{{"en", 1}}, false},
+		{" en : 1 , de:0.4,, es:0.25 ", []ratioEntry{{"en", 1}, {"de", 0.4}, {"es", 0.25}}, false},
+		{"23:5,553:-1", []ratioEntry{{"23", 5}, {"553", -1}}, false},
+		{"en", nil, true},
+		{"en:x", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRatioList(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRatioList(%q): got error %v, want error %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseRatioList(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+const DefaultLocales = "en:1.0"
+
+// LocaleCoverage is a locale and the fraction of rows translated into it
+type LocaleCoverage struct {
+	Locale   string
+	Coverage float64
+}
+
+// LocaleConfig lists the locales to generate. The first locale is the default one: it must
+// cover every row and is used for url_path and other non-localized columns.
+type LocaleConfig struct {
+	Locales []LocaleCoverage
+}
+
+// localeDictionary holds the words used to generate localized strings for one locale
+type localeDictionary struct {
+	product     string
+	description string // fmt template receiving the localized title
+	adjectives  []string
+	nouns       []string
+	// Category name parts, keyed by the English word used in subcategory names
+	categoryWords map[string]string
+	// Letters replaced before slugifying (e.g. ä -> ae in German)
+	transliteration *strings.Replacer
+}
+
+// Letters with diacritics shared by most latin-script locales
+var defaultTransliteration = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "ß", "ss", "œ", "oe", "æ", "ae",
+)
+
+var localeDictionaries = map[string]localeDictionary{
+	"en": {
+		product:     "Product",
+		description: "Description for %s",
+		adjectives:  adjectives,
+		nouns:       nouns,
+	},
+	"de": {
+		product:     "Produkt",
+		description: "Beschreibung für %s",
+		adjectives:  []string{"abstrakte", "bunte", "elegante", "festliche", "florale", "frische", "geometrische", "goldene", "handgemachte", "klassische", "moderne", "natürliche", "verspielte", "schöne", "süße", "große"},
+		nouns:       []string{"Blumen", "Grafik", "Hintergründe", "Illustrationen", "Karten", "Kränze", "Muster", "Rahmen", "Schriften", "Sticker", "Symbole", "Vorlagen", "Etiketten", "Aquarelle", "Plakate", "Gestaltung"},
+		categoryWords: map[string]string{
+			"Modern": "Moderne", "Vintage": "Vintage", "Classic": "Klassische", "Premium": "Premium", "Professional": "Professionelle",
+			"Creative": "Kreative", "Elegant": "Elegante", "Bold": "Kräftige", "Minimal": "Minimalistische", "Decorative": "Dekorative",
+			"Designs": "Designs", "Templates": "Vorlagen", "Graphics": "Grafiken", "Elements": "Elemente", "Patterns": "Muster",
+			"Styles": "Stile", "Collections": "Kollektionen", "Sets": "Sets", "Packs": "Pakete", "Kits": "Baukästen",
+		},
+		transliteration: strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss"),
+	},
+	"es": {
+		product:     "Producto",
+		description: "Descripción de %s",
+		adjectives:  []string{"abstracto", "artístico", "clásico", "creativo", "decorativo", "elegante", "festivo", "floral", "geométrico", "mágico", "moderno", "rústico", "pequeño", "único", "vibrante", "otoñal"},
+		nouns:       []string{"diseño", "ilustración", "patrón", "marco", "fondo", "tarjeta", "etiqueta", "logotipo", "ornamento", "póster", "símbolo", "plantilla", "acuarela", "corazón", "niño", "paquete"},
+		categoryWords: map[string]string{
+			"Modern": "Modernos", "Vintage": "Vintage", "Classic": "Clásicos", "Premium": "Premium", "Professional": "Profesionales",
+			"Creative": "Creativos", "Elegant": "Elegantes", "Bold": "Audaces", "Minimal": "Minimalistas", "Decorative": "Decorativos",
+			"Designs": "Diseños", "Templates": "Plantillas", "Graphics": "Gráficos", "Elements": "Elementos", "Patterns": "Patrones",
+			"Styles": "Estilos", "Collections": "Colecciones", "Sets": "Conjuntos", "Packs": "Paquetes", "Kits": "Kits",
+		},
+	},
+	"fr": {
+		product:     "Produit",
+		description: "Description de %s",
+		adjectives:  []string{"abstrait", "artistique", "classique", "créatif", "décoratif", "élégant", "festif", "floral", "géométrique", "magique", "moderne", "rustique", "joli", "unique", "coloré", "doré"},
+		nouns:       []string{"motif", "cadre", "fond", "carte", "étiquette", "logo", "ornement", "affiche", "symbole", "modèle", "aquarelle", "couronne", "bouquet", "icône", "écusson", "collection"},
+		categoryWords: map[string]string{
+			"Modern": "Modernes", "Vintage": "Vintage", "Classic": "Classiques", "Premium": "Premium", "Professional": "Professionnels",
+			"Creative": "Créatifs", "Elegant": "Élégants", "Bold": "Audacieux", "Minimal": "Minimalistes", "Decorative": "Décoratifs",
+			"Designs": "Designs", "Templates": "Modèles", "Graphics": "Graphiques", "Elements": "Éléments", "Patterns": "Motifs",
+			"Styles": "Styles", "Collections": "Collections", "Sets": "Ensembles", "Packs": "Packs", "Kits": "Kits",
+		},
+	},
+	"it": {
+		product:     "Prodotto",
+		description: "Descrizione di %s",
+		adjectives:  []string{"astratto", "artistico", "classico", "creativo", "decorativo", "elegante", "festivo", "floreale", "geometrico", "magico", "moderno", "rustico", "grazioso", "unico", "colorato", "dorato"},
+		nouns:       []string{"motivo", "cornice", "sfondo", "biglietto", "etichetta", "logo", "ornamento", "poster", "simbolo", "modello", "acquerello", "ghirlanda", "bouquet", "icona", "città", "collezione"},
+		categoryWords: map[string]string{
+			"Modern": "Moderni", "Vintage": "Vintage", "Classic": "Classici", "Premium": "Premium", "Professional": "Professionali",
+			"Creative": "Creativi", "Elegant": "Eleganti", "Bold": "Audaci", "Minimal": "Minimalisti", "Decorative": "Decorativi",
+			"Designs": "Design", "Templates": "Modelli", "Graphics": "Grafiche", "Elements": "Elementi", "Patterns": "Motivi",
+			"Styles": "Stili", "Collections": "Collezioni", "Sets": "Set", "Packs": "Pacchetti", "Kits": "Kit",
+		},
+	},
+	"pt": {
+		product:     "Produto",
+		description: "Descrição de %s",
+		adjectives:  []string{"abstrato", "artístico", "clássico", "criativo", "decorativo", "elegante", "festivo", "floral", "geométrico", "mágico", "moderno", "rústico", "bonito", "único", "colorido", "dourado"},
+		nouns:       []string{"padrão", "moldura", "fundo", "cartão", "etiqueta", "logotipo", "ornamento", "pôster", "símbolo", "modelo", "aquarela", "coroa", "buquê", "ícone", "coração", "coleção"},
+		categoryWords: map[string]string{
+			"Modern": "Modernos", "Vintage": "Vintage", "Classic": "Clássicos", "Premium": "Premium", "Professional": "Profissionais",
+			"Creative": "Criativos", "Elegant": "Elegantes", "Bold": "Ousados", "Minimal": "Minimalistas", "Decorative": "Decorativos",
+			"Designs": "Designs", "Templates": "Modelos", "Graphics": "Gráficos", "Elements": "Elementos", "Patterns": "Padrões",
+			"Styles": "Estilos", "Collections": "Coleções", "Sets": "Conjuntos", "Packs": "Pacotes", "Kits": "Kits",
+		},
+	},
+	"nl": {
+		product:     "Product",
+		description: "Beschrijving van %s",
+		adjectives:  []string{"abstracte", "artistieke", "klassieke", "creatieve", "decoratieve", "elegante", "feestelijke", "bloemige", "geometrische", "magische", "moderne", "rustieke", "mooie", "unieke", "kleurrijke", "gouden"},
+		nouns:       []string{"patronen", "lijsten", "achtergronden", "kaarten", "etiketten", "logo's", "ornamenten", "posters", "symbolen", "sjablonen", "aquarellen", "kransen", "boeketten", "iconen", "stickers", "collecties"},
+		categoryWords: map[string]string{
+			"Modern": "Moderne", "Vintage": "Vintage", "Classic": "Klassieke", "Premium": "Premium", "Professional": "Professionele",
+			"Creative": "Creatieve", "Elegant": "Elegante", "Bold": "Gedurfde", "Minimal": "Minimalistische", "Decorative": "Decoratieve",
+			"Designs": "Ontwerpen", "Templates": "Sjablonen", "Graphics": "Afbeeldingen", "Elements": "Elementen", "Patterns": "Patronen",
+			"Styles": "Stijlen", "Collections": "Collecties", "Sets": "Sets", "Packs": "Pakketten", "Kits": "Kits",
+		},
+	},
+}
+
+// Translated names of the top-level categories, keyed by category ID then locale
+var categoryNameTranslations = map[int64]map[string]string{
+	553:  {"de": "Grafiken", "es": "Gráficos", "fr": "Graphiques", "it": "Grafiche", "pt": "Gráficos", "nl": "Afbeeldingen"},
+	23:   {"de": "Schriftarten", "es": "Fuentes", "fr": "Polices", "it": "Caratteri", "pt": "Fontes", "nl": "Lettertypen"},
+	26:   {"de": "Basteln", "es": "Manualidades", "fr": "Loisirs créatifs", "it": "Fai da te", "pt": "Artesanato", "nl": "Knutselen"},
+	735:  {"de": "Stickerei", "es": "Bordado", "fr": "Broderie", "it": "Ricamo", "pt": "Bordado", "nl": "Borduren"},
+	2245: {"de": "Laserschneiden", "es": "Corte láser", "fr": "Découpe laser", "it": "Taglio laser", "pt": "Corte a laser", "nl": "Lasersnijden"},
+	546:  {"de": "Bundles", "es": "Lotes", "fr": "Lots", "it": "Pacchetti", "pt": "Pacotes", "nl": "Bundels"},
+	1850: {"de": "3D-SVG", "es": "SVG 3D", "fr": "SVG 3D", "it": "SVG 3D", "pt": "SVG 3D", "nl": "3D-SVG"},
+	2244: {"de": "3D-Druck", "es": "Impresión 3D", "fr": "Impression 3D", "it": "Stampa 3D", "pt": "Impressão 3D", "nl": "3D-printen"},
+	2246: {"de": "Stricken", "es": "Tejido", "fr": "Tricot", "it": "Maglia", "pt": "Tricô", "nl": "Breien"},
+}
+
+// ParseLocaleConfig parses a "-locales" value such as "en:1.0,de:0.4,es:0.25"
+func ParseLocaleConfig(s string) (LocaleConfig, error) {
+	entries, err := parseRatioList(s)
+	if err != nil {
+		return LocaleConfig{}, fmt.Errorf("invalid locales: %w", err)
+	}
+	locales := make([]LocaleCoverage, len(entries))
+	for i, e := range entries {
+		locales[i] = LocaleCoverage{Locale: e.name, Coverage: e.value}
+	}
+	if len(locales) == 0 {
+		return LocaleConfig{}, fmt.Errorf("at least one locale is required")
+	}
+
+	seen := make(map[string]bool)
+	for i, l := range locales {
+		if _, ok := localeDictionaries[l.Locale]; !ok {
+			return LocaleConfig{}, fmt.Errorf("unsupported locale %q", l.Locale)
+		}
+		if seen[l.Locale] {
+			return LocaleConfig{}, fmt.Errorf("locale %q listed twice", l.Locale)
+		}
+		seen[l.Locale] = true
+		if l.Coverage < 0 || l.Coverage > 1 {
+			return LocaleConfig{}, fmt.Errorf("coverage for locale %q must be between 0 and 1, got %.2f", l.Locale, l.Coverage)
+		}
+		if i == 0 && l.Coverage != 1 {
+			return LocaleConfig{}, fmt.Errorf("default locale %q must have a coverage of 1.0", l.Locale)
+		}
+	}
+	return LocaleConfig{Locales: locales}, nil
+}
+
+func (cfg LocaleConfig) String() string {
+	parts := make([]string, len(cfg.Locales))
+	for i, l := range cfg.Locales {
+		parts[i] = fmt.Sprintf("%s %.0f%%", l.Locale, l.Coverage*100)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// defaultLocale returns the locale every row is translated into
+func (cfg LocaleConfig) defaultLocale() string {
+	return cfg.Locales[0].Locale
+}
+
+// pick returns the locales a single row is translated into; the default locale is always included
+func (cfg LocaleConfig) pick(rng *rand.Rand) []string {
+	picked := make([]string, 0, len(cfg.Locales))
+	for i, l := range cfg.Locales {
+		if i == 0 || rng.Float64() < l.Coverage {
+			picked = append(picked, l.Locale)
+		}
+	}
+	return picked
+}
+
+// localeSlug transliterates locale specific letters and slugifies the result
+func localeSlug(locale, s string) string {
+	s = strings.ToLower(s)
+	if t := localeDictionaries[locale].transliteration; t != nil {
+		s = t.Replace(s)
+	}
+	return slugify(defaultTransliteration.Replace(s))
+}
+
+// localizedProduct holds the localized title, slug and description of a product, keyed by locale
+type localizedProduct struct {
+	title       map[string]string
+	slug        map[string]string
+	description map[string]string
+}
+
+func generateLocalizedProduct(rng *rand.Rand, cfg LocaleConfig, productID int64) localizedProduct {
+	locales := cfg.pick(rng)
+	p := localizedProduct{
+		title:       make(map[string]string, len(locales)),
+		slug:        make(map[string]string, len(locales)),
+		description: make(map[string]string, len(locales)),
+	}
+	for _, locale := range locales {
+		dict := localeDictionaries[locale]
+		title := fmt.Sprintf("%s %d - %s %s", dict.product, productID, dict.adjectives[rng.Intn(len(dict.adjectives))], dict.nouns[rng.Intn(len(dict.nouns))])
+		p.title[locale] = title
+		p.slug[locale] = localeSlug(locale, title)
+		p.description[locale] = fmt.Sprintf(dict.description, title)
+	}
+	return p
+}
+
+// localizedCategory returns the name and description translations of a category. English words
+// in generated subcategory names are translated through each locale's category dictionary.
+func localizedCategory(rng *rand.Rand, cfg LocaleConfig, categoryID int64, name string) (names, descriptions map[string]string) {
+	names = make(map[string]string)
+	descriptions = make(map[string]string)
+	for _, locale := range cfg.pick(rng) {
+		dict := localeDictionaries[locale]
+		translated := name
+		if fixed, ok := categoryNameTranslations[categoryID][locale]; ok {
+			translated = fixed
+		} else if dict.categoryWords != nil {
+			words := strings.Fields(name)
+			for i, w := range words {
+				if t, ok := dict.categoryWords[w]; ok {
+					words[i] = t
+				}
+			}
+			translated = strings.Join(words, " ")
+		}
+		names[locale] = translated
+		descriptions[locale] = fmt.Sprintf(dict.description, translated)
+	}
+	return names, descriptions
+}
+
+// mustJSON encodes a value that is known to be serializable
+func mustJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParseLocaleConfig(t *testing.T) {
+	tests := []struct {
+		in      string
+		locales int
+		wantErr bool
+	}{
+		{"en:1.0", 1, false},
+		{"de:1,en:0.5,fr:0", 3, false},
+		{"", 0, true},
+		{"en:0.9", 0, true},      // Default locale must cover every row
+		{"en:1,xx:0.5", 0, true}, // Unsupported locale
+		{"en:1,de:0.5,de:0.2", 0, true},
+		{"en:1,de:1.5", 0, true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseLocaleConfig(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLocaleConfig(%q): got error %v, want error %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if len(cfg.Locales) != tt.locales {
+			t.Errorf("ParseLocaleConfig(%q) has %d locales, want %d", tt.in, len(cfg.Locales), tt.locales)
+		}
+	}
+}
+
+func TestLocaleConfigPick(t *testing.T) {
+	cfg, err := ParseLocaleConfig("en:1,de:0.5,fr:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	german := 0
+	const n = 10000
+	for i := 0; i < n; i++ {
+		picked := cfg.pick(rng)
+		if picked[0] != "en" {
+			t.Fatalf("default locale missing from %v", picked)
+		}
+		for _, locale := range picked[1:] {
+			switch locale {
+			case "de":
+				german++
+			case "fr":
+				t.Fatalf("locale fr with coverage 0 picked")
+			}
+		}
+	}
+	if share := float64(german) / n; share < 0.45 || share > 0.55 {
+		t.Errorf("de picked for %.2f of the rows, want 0.5", share)
+	}
+}
+
+func TestLocaleSlug(t *testing.T) {
+	tests := []struct {
+		locale, in, want string
+	}{
+		{"en", "Product 42 - Modern Frames", "product-42-modern-frames"},
+		{"de", "Produkt 42 - Schöne Hintergründe", "produkt-42-schoene-hintergruende"},
+		{"de", "Große Kränze", "grosse-kraenze"},
+		{"es", "Producto 7 - Diseño Otoñal", "producto-7-diseno-otonal"},
+		{"fr", "Produit 3 - Élégant Écusson", "produit-3-elegant-ecusson"},
+	}
+	for _, tt := range tests {
+		if got := localeSlug(tt.locale, tt.in); got != tt.want {
+			t.Errorf("localeSlug(%q, %q) = %q, want %q", tt.locale, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizedCategory(t *testing.T) {
+	cfg, err := ParseLocaleConfig("en:1,de:1,fr:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	names, descriptions := localizedCategory(rng, cfg, 553, "Graphics")
+	if names["en"] != "Graphics" || names["de"] != "Grafiken" || names["fr"] != "Graphiques" {
+		t.Errorf("top-level category names %v", names)
+	}
+	if !strings.Contains(descriptions["de"], "Grafiken") {
+		t.Errorf("German description %q doesn't name the category", descriptions["de"])
+	}
+
+	names, _ = localizedCategory(rng, cfg, 10001, "Modern Templates")
+	if names["de"] != "Moderne Vorlagen" || names["fr"] != "Modernes Modèles" {
+		t.Errorf("subcategory names %v", names)
+	}
+}
@@ -0,0 +1,277 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultImagesMean    = 6.0
+	DefaultAssetsMean    = 3.0
+	DefaultMetadataScale = 1.0
+	imageCDNBaseURL      = "https://cdn.example.com/products"
+	payloadCountSpread   = 0.6 // Sigma of the log-normal used for image and asset counts
+)
+
+// PayloadConfig controls the size of the jsonb columns of generated products
+type PayloadConfig struct {
+	ImagesMean    float64 // Average number of gallery images per product
+	AssetsMean    float64 // Average number of downloadable files per product
+	MetadataScale float64 // Multiplier for the number of keywords and features in metadata
+}
+
+// assetKind describes one downloadable file type and its size range in kilobytes
+type assetKind struct {
+	extension string
+	mimeType  string
+	minKB     int
+	maxKB     int
+}
+
+// payloadTemplate drives the jsonb payloads of one product_type
+type payloadTemplate struct {
+	imageFormats []string
+	imageSizes   [][2]int
+	assets       []assetKind
+	software     []string
+	licenses     []string
+	features     []string
+}
+
+var payloadTemplates = map[string]payloadTemplate{
+	"digital": {
+		imageFormats: []string{"jpg", "png", "webp"},
+		imageSizes:   [][2]int{{1160, 772}, {2000, 1333}, {3000, 2000}, {1500, 1500}},
+		assets: []assetKind{
+			{"svg", "image/svg+xml", 20, 4000},
+			{"png", "image/png", 200, 25000},
+			{"eps", "application/postscript", 300, 30000},
+			{"pdf", "application/pdf", 100, 20000},
+			{"ai", "application/illustrator", 500, 60000},
+			{"zip", "application/zip", 1000, 500000},
+		},
+		software: []string{"Adobe Illustrator", "Adobe Photoshop", "Cricut Design Space", "Silhouette Studio", "Canva", "Procreate", "Affinity Designer", "Inkscape"},
+		licenses: []string{"standard", "extended", "personal"},
+		features: []string{"layered", "vector", "print ready", "transparent background", "editable text", "high resolution", "cut file", "sublimation"},
+	},
+	"font": {
+		imageFormats: []string{"jpg", "png"},
+		imageSizes:   [][2]int{{1160, 772}, {2000, 1333}},
+		assets: []assetKind{
+			{"otf", "font/otf", 30, 2000},
+			{"ttf", "font/ttf", 30, 2000},
+			{"woff", "font/woff", 20, 1000},
+			{"woff2", "font/woff2", 15, 800},
+			{"zip", "application/zip", 200, 20000},
+		},
+		software: []string{"Microsoft Word", "Adobe Illustrator", "Adobe Photoshop", "Canva", "Cricut Design Space", "Procreate", "Affinity Publisher"},
+		licenses: []string{"desktop", "webfont", "app", "extended"},
+		features: []string{"ligatures", "alternates", "multilingual", "swashes", "PUA encoded", "variable weight", "italic"},
+	},
+	"bundle": {
+		imageFormats: []string{"jpg", "webp"},
+		imageSizes:   [][2]int{{1160, 772}, {2000, 1333}, {3000, 2000}},
+		assets: []assetKind{
+			{"zip", "application/zip", 10000, 2000000},
+			{"pdf", "application/pdf", 100, 5000},
+		},
+		software: []string{"Adobe Illustrator", "Adobe Photoshop", "Cricut Design Space", "Silhouette Studio", "Canva", "Procreate", "Microsoft Word"},
+		licenses: []string{"standard", "extended"},
+		features: []string{"bonus items", "mixed formats", "commercial use", "limited time", "mega pack"},
+	},
+	"3d-model": {
+		imageFormats: []string{"jpg", "png"},
+		imageSizes:   [][2]int{{1500, 1500}, {2000, 2000}},
+		assets: []assetKind{
+			{"stl", "model/stl", 500, 100000},
+			{"obj", "model/obj", 500, 150000},
+			{"3mf", "model/3mf", 300, 80000},
+			{"svg", "image/svg+xml", 20, 4000},
+			{"dxf", "image/vnd.dxf", 20, 5000},
+		},
+		software: []string{"Cura", "PrusaSlicer", "Blender", "Fusion 360", "LightBurn", "Glowforge", "xTool Creative Space"},
+		licenses: []string{"standard", "extended", "personal"},
+		features: []string{"supports included", "pre-sliced", "multi-part", "layered", "test cut included"},
+	},
+	"pattern": {
+		imageFormats: []string{"jpg", "png"},
+		imageSizes:   [][2]int{{1160, 772}, {1500, 1500}},
+		assets: []assetKind{
+			{"pdf", "application/pdf", 200, 15000},
+			{"pes", "application/octet-stream", 20, 2000},
+			{"dst", "application/octet-stream", 20, 2000},
+			{"jef", "application/octet-stream", 20, 2000},
+			{"zip", "application/zip", 500, 50000},
+		},
+		software: []string{"Hatch Embroidery", "Embrilliance", "Wilcom", "Brother PE-Design", "Adobe Acrobat"},
+		licenses: []string{"standard", "extended", "personal"},
+		features: []string{"beginner friendly", "step by step", "multiple sizes", "stitch count included", "color chart"},
+	},
+}
+
+// productPayload holds the serialized jsonb columns of one product
+type productPayload struct {
+	mainImage string
+	images    string
+	assets    string
+	metadata  string
+}
+
+type productImage struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+	Alt    string `json:"alt"`
+}
+
+type productAsset struct {
+	FileName  string `json:"file_name"`
+	FileType  string `json:"file_type"`
+	MimeType  string `json:"mime_type"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+type productLicense struct {
+	Type          string `json:"type"`
+	CommercialUse bool   `json:"commercial_use"`
+	PODAllowed    bool   `json:"pod_allowed"`
+	MaxCopies     int    `json:"max_copies,omitempty"`
+}
+
+type productMetadata struct {
+	License               productLicense `json:"license"`
+	SoftwareCompatibility []string       `json:"software_compatibility"`
+	FileTypes             []string       `json:"file_types"`
+	Features              []string       `json:"features"`
+	Keywords              []string       `json:"keywords"`
+	DPI                   int            `json:"dpi,omitempty"`
+	ColorMode             string         `json:"color_mode,omitempty"`
+}
+
+func (cfg PayloadConfig) validate() error {
+	if cfg.ImagesMean < 1 {
+		return fmt.Errorf("average images per product must be >= 1, got %.2f", cfg.ImagesMean)
+	}
+	if cfg.AssetsMean < 1 {
+		return fmt.Errorf("average assets per product must be >= 1, got %.2f", cfg.AssetsMean)
+	}
+	if cfg.MetadataScale < 0 {
+		return fmt.Errorf("metadata scale must be >= 0, got %.2f", cfg.MetadataScale)
+	}
+	return nil
+}
+
+// payloadCount draws a count from a log-normal distribution with the given mean, at least 1
+func payloadCount(rng *rand.Rand, mean float64) int {
+	// exp(sigma^2/2) is the mean of exp(sigma*Z), dividing by it keeps the requested mean
+	factor := math.Exp(payloadCountSpread*rng.NormFloat64()) / math.Exp(payloadCountSpread*payloadCountSpread/2)
+	n := int(math.Round(mean * factor))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// pickDistinct returns up to n distinct random elements of items
+func pickDistinct(rng *rand.Rand, items []string, n int) []string {
+	if n > len(items) {
+		n = len(items)
+	}
+	picked := make([]string, n)
+	for i, j := range rng.Perm(len(items))[:n] {
+		picked[i] = items[j]
+	}
+	return picked
+}
+
+func generateProductPayload(rng *rand.Rand, cfg PayloadConfig, productID int64, productType, title string) productPayload {
+	tmpl, ok := payloadTemplates[productType]
+	if !ok {
+		tmpl = payloadTemplates["digital"]
+	}
+
+	images := make([]productImage, payloadCount(rng, cfg.ImagesMean))
+	for i := range images {
+		size := tmpl.imageSizes[rng.Intn(len(tmpl.imageSizes))]
+		format := tmpl.imageFormats[rng.Intn(len(tmpl.imageFormats))]
+		images[i] = productImage{
+			URL:    fmt.Sprintf("%s/%d/%d-%dx%d.%s", imageCDNBaseURL, productID, i+1, size[0], size[1], format),
+			Width:  size[0],
+			Height: size[1],
+			Format: format,
+			Alt:    fmt.Sprintf("%s - preview %d", title, i+1),
+		}
+	}
+
+	baseName := localeSlug("en", title)
+	assets := make([]productAsset, payloadCount(rng, cfg.AssetsMean))
+	fileTypes := make(map[string]bool)
+	for i := range assets {
+		kind := tmpl.assets[rng.Intn(len(tmpl.assets))]
+		fileTypes[kind.extension] = true
+		assets[i] = productAsset{
+			FileName:  fmt.Sprintf("%s-%d.%s", baseName, i+1, kind.extension),
+			FileType:  kind.extension,
+			MimeType:  kind.mimeType,
+			SizeBytes: int64(kind.minKB+rng.Intn(kind.maxKB-kind.minKB+1)) * 1024,
+		}
+	}
+
+	metadata := productMetadata{
+		License: productLicense{
+			Type:          tmpl.licenses[rng.Intn(len(tmpl.licenses))],
+			CommercialUse: rng.Float64() < 0.8,
+			PODAllowed:    rng.Float64() < 0.6,
+		},
+		SoftwareCompatibility: pickDistinct(rng, tmpl.software, 1+rng.Intn(len(tmpl.software))),
+		Features:              pickDistinct(rng, tmpl.features, int(math.Round(float64(1+rng.Intn(len(tmpl.features)))*cfg.MetadataScale))),
+		Keywords:              make([]string, int(math.Round(float64(5+rng.Intn(20))*cfg.MetadataScale))),
+	}
+	if metadata.License.Type == "standard" || metadata.License.Type == "personal" {
+		metadata.License.MaxCopies = []int{500, 1000, 5000, 10000}[rng.Intn(4)]
+	}
+	for ext := range fileTypes {
+		metadata.FileTypes = append(metadata.FileTypes, ext)
+	}
+	sort.Strings(metadata.FileTypes)
+	for i := range metadata.Keywords {
+		metadata.Keywords[i] = strings.ReplaceAll(generateRandomTagSlug(rng), "-", " ")
+	}
+	if productType != "font" {
+		metadata.DPI = []int{72, 150, 300, 300, 600}[rng.Intn(5)]
+		metadata.ColorMode = []string{"RGB", "RGB", "CMYK"}[rng.Intn(3)]
+	}
+
+	return productPayload{
+		mainImage: mustJSON(images[0]),
+		images:    mustJSON(images),
+		assets:    mustJSON(assets),
+		metadata:  mustJSON(metadata),
+	}
+}
+
+// reportProductRowWidth prints the average stored size of the products inserted in [startID, endID]
+func reportProductRowWidth(db *sql.DB, startID, endID int64) error {
+	var row, mainImage, images, assets, metadata sql.NullFloat64
+	err := db.QueryRow(`
+		SELECT AVG(pg_column_size(p.*)),
+		       AVG(pg_column_size(p.main_image)),
+		       AVG(pg_column_size(p.images)),
+		       AVG(pg_column_size(p.assets)),
+		       AVG(pg_column_size(p.metadata))
+		FROM product p
+		WHERE p.product_id BETWEEN $1 AND $2
+	`, startID, endID).Scan(&row, &mainImage, &images, &assets, &metadata)
+	if err != nil {
+		return fmt.Errorf("failed to measure product row width: %w", err)
+	}
+
+	fmt.Printf("  Average row width: %.0f bytes (main_image %.0f, images %.0f, assets %.0f, metadata %.0f)\n",
+		row.Float64, mainImage.Float64, images.Float64, assets.Float64, metadata.Float64)
+	return nil
+}
@@ -0,0 +1,93 @@
+package loader
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestPayloadConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     PayloadConfig
+		wantErr bool
+	}{
+		{PayloadConfig{ImagesMean: DefaultImagesMean, AssetsMean: DefaultAssetsMean, MetadataScale: DefaultMetadataScale}, false},
+		{PayloadConfig{ImagesMean: 1, AssetsMean: 1, MetadataScale: 0}, false},
+		{PayloadConfig{ImagesMean: 0.5, AssetsMean: 1}, true},
+		{PayloadConfig{ImagesMean: 1, AssetsMean: 0}, true},
+		{PayloadConfig{ImagesMean: 1, AssetsMean: 1, MetadataScale: -1}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: got error %v, want error %t", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPayloadCount(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, mean := range []float64{3, 6, 20} {
+		sum := 0
+		const n = 20000
+		for i := 0; i < n; i++ {
+			count := payloadCount(rng, mean)
+			if count < 1 {
+				t.Fatalf("mean %g: count %d below 1", mean, count)
+			}
+			sum += count
+		}
+		// Rounding and the floor of 1 push the mean up a little
+		if got := float64(sum) / n; got < mean*0.95 || got > mean*1.2 {
+			t.Errorf("mean %g: got %.2f", mean, got)
+		}
+	}
+}
+
+func TestPickDistinct(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range []struct{ n, want int }{{0, 0}, {2, 2}, {4, 4}, {9, 4}} {
+		picked := pickDistinct(rng, items, tt.n)
+		seen := make(map[string]bool)
+		for _, item := range picked {
+			if seen[item] {
+				t.Errorf("n=%d: %q picked twice", tt.n, item)
+			}
+			seen[item] = true
+		}
+		if len(picked) != tt.want {
+			t.Errorf("n=%d: picked %d items, want %d", tt.n, len(picked), tt.want)
+		}
+	}
+}
+
+func TestGenerateProductPayload(t *testing.T) {
+	cfg := PayloadConfig{ImagesMean: DefaultImagesMean, AssetsMean: DefaultAssetsMean, MetadataScale: DefaultMetadataScale}
+	rng := rand.New(rand.NewSource(1))
+	for productType := range payloadTemplates {
+		p := generateProductPayload(rng, cfg, 42, productType, "Product 42 - Modern Frames")
+
+		var mainImage productImage
+		var images []productImage
+		var assets []productAsset
+		var metadata productMetadata
+		for _, v := range []struct {
+			json   string
+			target interface{}
+		}{{p.mainImage, &mainImage}, {p.images, &images}, {p.assets, &assets}, {p.metadata, &metadata}} {
+			if err := json.Unmarshal([]byte(v.json), v.target); err != nil {
+				t.Fatalf("%s: invalid json %s: %v", productType, v.json, err)
+			}
+		}
+
+		if len(images) == 0 || len(assets) == 0 || mainImage != images[0] {
+			t.Errorf("%s: %d images, %d assets, main image %+v", productType, len(images), len(assets), mainImage)
+		}
+		if productType == "font" && metadata.DPI != 0 {
+			t.Errorf("font with a DPI of %d", metadata.DPI)
+		}
+		if len(metadata.FileTypes) == 0 || len(metadata.SoftwareCompatibility) == 0 {
+			t.Errorf("%s: metadata without file types or software %+v", productType, metadata)
+		}
+	}
+}
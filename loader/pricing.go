@@ -0,0 +1,143 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultCharmRate = 0.85 // Share of paid products priced at X.99
+	minPaidPrice     = 99   // Cheapest paid product, in cents
+)
+
+// PriceModel is the log-normal price distribution of one category
+type PriceModel struct {
+	MedianCents int64   // Median price of paid products
+	Spread      float64 // Sigma of the log-normal distribution
+	FreeShare   float64 // Share of free products
+}
+
+// PricingConfig controls product prices across categories
+type PricingConfig struct {
+	Models    map[int64]PriceModel // Per top-level category, defaults to the category configuration
+	CharmRate float64
+}
+
+// defaultPriceModels returns the price models of the hardcoded categories
+func defaultPriceModels() map[int64]PriceModel {
+	models := make(map[int64]PriceModel, len(categories))
+	for _, cat := range categories {
+		models[cat.ID] = cat.Price
+	}
+	return models
+}
+
+// ParsePricingConfig applies "-price-models" overrides such as "553=500/0.9/0.05,23=1500/0.8/0.03"
+// (category=median cents/spread/free share) on top of the category configuration
+func ParsePricingConfig(overrides string, charmRate float64) (PricingConfig, error) {
+	cfg := PricingConfig{Models: defaultPriceModels(), CharmRate: charmRate}
+	if charmRate < 0 || charmRate > 1 {
+		return cfg, fmt.Errorf("charm rate must be between 0 and 1, got %.2f", charmRate)
+	}
+
+	for _, item := range strings.Split(overrides, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, spec, ok := strings.Cut(item, "=")
+		parts := strings.Split(spec, "/")
+		if !ok || len(parts) != 3 {
+			return cfg, fmt.Errorf("invalid price model %q, expected category=median/spread/free", item)
+		}
+		categoryID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid category in price model %q: %w", item, err)
+		}
+		if _, exists := cfg.Models[categoryID]; !exists {
+			return cfg, fmt.Errorf("unknown category %d in price model %q", categoryID, item)
+		}
+		median, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || median < minPaidPrice {
+			return cfg, fmt.Errorf("invalid median in price model %q, expected cents >= %d", item, minPaidPrice)
+		}
+		spread, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || spread < 0 {
+			return cfg, fmt.Errorf("invalid spread in price model %q", item)
+		}
+		free, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || free < 0 || free > 1 {
+			return cfg, fmt.Errorf("invalid free share in price model %q", item)
+		}
+		cfg.Models[categoryID] = PriceModel{MedianCents: median, Spread: spread, FreeShare: free}
+	}
+	return cfg, nil
+}
+
+// price draws a price in cents for a product of the given top-level category
+func (cfg PricingConfig) price(rng *rand.Rand, categoryID int64) int64 {
+	model, ok := cfg.Models[categoryID]
+	if !ok {
+		model = categories[0].Price
+	}
+	if rng.Float64() < model.FreeShare {
+		return 0
+	}
+
+	cents := int64(math.Round(float64(model.MedianCents) * math.Exp(model.Spread*rng.NormFloat64())))
+	if rng.Float64() < cfg.CharmRate {
+		// Round up to the next whole unit and drop a cent: 437 -> 499, 1210 -> 1299
+		cents = (cents+99)/100*100 - 1
+	}
+	if cents < minPaidPrice {
+		cents = minPaidPrice
+	}
+	return cents
+}
+
+// verifyPrices prints price percentiles per category
+func verifyPrices(db *sql.DB, _ *verifyReport) error {
+	rows, err := db.Query(`
+		SELECT category_id,
+		       COUNT(*),
+		       AVG(CASE WHEN price_in_cents = 0 THEN 1.0 ELSE 0.0 END),
+		       AVG(CASE WHEN price_in_cents % 100 = 99 THEN 1.0 ELSE 0.0 END),
+		       percentile_disc(ARRAY[0.1, 0.25, 0.5, 0.75, 0.9, 0.99]) WITHIN GROUP (ORDER BY price_in_cents)
+		FROM product
+		GROUP BY category_id
+		ORDER BY COUNT(*) DESC
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to compute price percentiles: %w", err)
+	}
+	defer rows.Close()
+
+	fmt.Printf("  %-14s %10s %6s %6s %8s %8s %8s %8s %8s %8s\n", "Category", "Products", "Free", "X.99", "p10", "p25", "p50", "p75", "p90", "p99")
+	for rows.Next() {
+		var categoryID, count int64
+		var free, charm float64
+		var percentiles pq.Int64Array
+		if err := rows.Scan(&categoryID, &count, &free, &charm, &percentiles); err != nil {
+			return fmt.Errorf("failed to scan price percentiles: %w", err)
+		}
+
+		name := fmt.Sprintf("%d", categoryID)
+		for _, cat := range categories {
+			if cat.ID == categoryID {
+				name = cat.Slug
+			}
+		}
+		fmt.Printf("  %-14s %10d %5.1f%% %5.1f%%", name, count, free*100, charm*100)
+		for _, cents := range percentiles {
+			fmt.Printf(" %8.2f", float64(cents)/100)
+		}
+		fmt.Println()
+	}
+	return rows.Err()
+}
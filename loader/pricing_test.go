@@ -0,0 +1,76 @@
+package loader
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCharmPricing(t *testing.T) {
+	tests := []struct {
+		median    int64
+		charmRate float64
+		want      int64
+	}{
+		{437, 1, 499},
+		{1210, 1, 1299},
+		{500, 1, 499},
+		{101, 1, 199},
+		{100, 1, 99},
+		{437, 0, 437},
+		{50, 0, 99}, // Paid products cost at least minPaidPrice
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		cfg := PricingConfig{Models: map[int64]PriceModel{553: {MedianCents: tt.median}}, CharmRate: tt.charmRate}
+		if got := cfg.price(rng, 553); got != tt.want {
+			t.Errorf("median %d, charm rate %g: price %d, want %d", tt.median, tt.charmRate, got, tt.want)
+		}
+	}
+}
+
+func TestPriceFreeShare(t *testing.T) {
+	cfg := PricingConfig{Models: map[int64]PriceModel{23: {MedianCents: 1500, Spread: 0.8, FreeShare: 0.1}}, CharmRate: DefaultCharmRate}
+	rng := rand.New(rand.NewSource(1))
+	free := 0
+	const n = 20000
+	for i := 0; i < n; i++ {
+		cents := cfg.price(rng, 23)
+		if cents == 0 {
+			free++
+		} else if cents < minPaidPrice {
+			t.Fatalf("paid product at %d cents", cents)
+		}
+	}
+	if share := float64(free) / n; share < 0.09 || share > 0.11 {
+		t.Errorf("%.3f of the products are free, want 0.1", share)
+	}
+}
+
+func TestParsePricingConfig(t *testing.T) {
+	tests := []struct {
+		overrides string
+		charmRate float64
+		want      PriceModel // Model of category 553
+		wantErr   bool
+	}{
+		{"", DefaultCharmRate, defaultPriceModels()[553], false},
+		{"553=500/0.9/0.05", 0.5, PriceModel{MedianCents: 500, Spread: 0.9, FreeShare: 0.05}, false},
+		{" 23=1500/0.8/0.03 , 553=99/0/1", 1, PriceModel{MedianCents: 99, Spread: 0, FreeShare: 1}, false},
+		{"", 1.5, PriceModel{}, true},
+		{"553=500/0.9", 0.5, PriceModel{}, true},
+		{"999=500/0.9/0.05", 0.5, PriceModel{}, true},
+		{"553=50/0.9/0.05", 0.5, PriceModel{}, true},
+		{"553=500/-1/0.05", 0.5, PriceModel{}, true},
+		{"553=500/0.9/2", 0.5, PriceModel{}, true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParsePricingConfig(tt.overrides, tt.charmRate)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePricingConfig(%q, %g): got error %v, want error %t", tt.overrides, tt.charmRate, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && cfg.Models[553] != tt.want {
+			t.Errorf("ParsePricingConfig(%q, %g): category 553 model %+v, want %+v", tt.overrides, tt.charmRate, cfg.Models[553], tt.want)
+		}
+	}
+}
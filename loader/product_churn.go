@@ -0,0 +1,315 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultProductChurnMix  = "update:0.7,retag:0.2,soft-delete:0.08,hard-delete:0.02"
+	productPriceChangeRate  = 0.5 // Share of updates that also change the price
+	productStatusChangeRate = 0.1 // Share of updates that also change the status
+)
+
+// productChurnTables are the tables whose bloat is reported, partitions included
+var productChurnTables = []string{"product", "product_tag", "product_product_category"}
+
+// productChurn updates, re-tags and deletes products to study autovacuum and index bloat
+type productChurn struct {
+	cfg        ProductConfig
+	tags       *idSampler
+	minProduct int64
+	maxProduct int64
+}
+
+// ImportProductChurn runs the product mutation workload and reports table and index growth
+func (l *Loader) ImportProductChurn(churn ChurnConfig, mixSpec string, cfg ProductConfig) error {
+	fmt.Print("\n=== Product Churn ===\n\n")
+
+	if err := churn.validate(); err != nil {
+		return err
+	}
+
+	c := &productChurn{cfg: cfg}
+	ops := map[string]churnOp{
+		"update":      c.update,
+		"retag":       c.retag,
+		"soft-delete": c.softDelete,
+		"hard-delete": c.hardDelete,
+	}
+	mix, err := parseChurnMix(mixSpec, ops)
+	if err != nil {
+		return err
+	}
+
+	// The product ID range only positions random scans, every touched row is read from the tables
+	err = l.db.QueryRow("SELECT COALESCE(MIN(product_id), 0), COALESCE(MAX(product_id), 0) FROM product").Scan(&c.minProduct, &c.maxProduct)
+	if err != nil {
+		return fmt.Errorf("failed to get product ID range: %w", err)
+	}
+	if c.maxProduct == 0 {
+		return fmt.Errorf("no products found in database - please import products first")
+	}
+
+	fmt.Println("Loading tags from database...")
+	c.tags, err = loadTagSampler(l.db)
+	if err != nil {
+		return err
+	}
+
+	before, err := tableBloatSnapshot(l.db, productChurnTables)
+	if err != nil {
+		return err
+	}
+
+	rate := "unlimited"
+	if churn.Rate > 0 {
+		rate = fmt.Sprintf("%.0f ops/s", churn.Rate)
+	}
+	fmt.Printf("Running for %s at %s, %d product(s) per operation, %d workers\n", churn.Duration, rate, churn.Batch, Workers)
+	fmt.Printf("Operations: %s\n\n", mix)
+
+	if err := l.runChurn(churn, mix, ops); err != nil {
+		return err
+	}
+
+	after, err := tableBloatSnapshot(l.db, productChurnTables)
+	if err != nil {
+		return err
+	}
+	printTableBloat(productChurnTables, before, after)
+	return nil
+}
+
+// churnTarget is a product picked by a churn operation
+type churnTarget struct {
+	productID  int64
+	categoryID int64
+}
+
+// lockTargets locks up to batch live products from a random position in tx, retrying from the start
+// of the table when nothing follows the pivot
+func (c *productChurn) lockTargets(tx *sql.Tx, rng *rand.Rand, batch int) ([]churnTarget, error) {
+	pivot := c.minProduct + rng.Int63n(c.maxProduct-c.minProduct+1)
+	for _, start := range []int64{pivot, c.minProduct} {
+		rows, err := tx.Query(`
+			SELECT product_id, category_id
+			FROM product
+			WHERE product_id >= $1 AND product_status <> 'deleted'
+			ORDER BY product_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		`, start, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		var targets []churnTarget
+		for rows.Next() {
+			var t churnTarget
+			if err := rows.Scan(&t.productID, &t.categoryID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			targets = append(targets, t)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(targets) > 0 || start == c.minProduct {
+			return targets, nil
+		}
+	}
+	return nil, nil
+}
+
+// inTx runs fn on the locked targets of one operation and commits
+func (c *productChurn) inTx(db *sql.DB, rng *rand.Rand, batch int, fn func(tx *sql.Tx, t churnTarget) (int64, error)) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	targets, err := c.lockTargets(tx, rng, batch)
+	if err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for _, t := range targets {
+		rows, err := fn(tx, t)
+		if err != nil {
+			return 0, err
+		}
+		changed += rows
+	}
+	return changed, tx.Commit()
+}
+
+// update rewrites the title, slug and description, sometimes the price and status, and bumps last_updated_at
+func (c *productChurn) update(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	return c.inTx(db, rng, batch, func(tx *sql.Tx, t churnTarget) (int64, error) {
+		text := generateLocalizedProduct(rng, c.cfg.Locales, t.productID)
+
+		set := []string{"title = title || $3::jsonb", "slug = slug || $4::jsonb", "description = description || $5::jsonb", "last_updated_at = NOW()"}
+		args := []interface{}{t.productID, t.categoryID, mustJSON(text.title), mustJSON(text.slug), mustJSON(text.description)}
+		if rng.Float64() < productPriceChangeRate {
+			args = append(args, c.cfg.Pricing.price(rng, t.categoryID))
+			set = append(set, fmt.Sprintf("price_in_cents = $%d", len(args)))
+		}
+		if rng.Float64() < productStatusChangeRate {
+			productStatus, status := c.cfg.Statuses.productStatus(rng)
+			args = append(args, productStatus, status)
+			set = append(set, fmt.Sprintf("product_status = $%d, status = $%d", len(args)-1, len(args)))
+		}
+
+		result, err := tx.Exec("UPDATE product SET "+strings.Join(set, ", ")+" WHERE product_id = $1 AND category_id = $2", args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+}
+
+// retag replaces every product_tag row of the product with a new random tag set
+func (c *productChurn) retag(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	return c.inTx(db, rng, batch, func(tx *sql.Tx, t churnTarget) (int64, error) {
+		if _, err := tx.Exec("DELETE FROM product_tag WHERE product_id = $1", t.productID); err != nil {
+			return 0, err
+		}
+
+		result, err := tx.Exec(`
+			INSERT INTO product_tag (product_id, tag_id, product_created_at)
+			SELECT p.product_id, u.tag_id, p.created_at
+			FROM unnest($3::bigint[]) AS u(tag_id)
+			INNER JOIN product p ON p.product_id = $1 AND p.category_id = $2
+			ON CONFLICT DO NOTHING
+		`, t.productID, t.categoryID, pq.Array(selectRandomTags(rng, c.tags)))
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("UPDATE product SET last_updated_at = NOW() WHERE product_id = $1 AND category_id = $2", t.productID, t.categoryID); err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+}
+
+// softDelete moves products to the deleted status, which the status column maps to trash
+func (c *productChurn) softDelete(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	return c.inTx(db, rng, batch, func(tx *sql.Tx, t churnTarget) (int64, error) {
+		result, err := tx.Exec(`
+			UPDATE product
+			SET product_status = 'deleted', status = $3, last_updated_at = NOW()
+			WHERE product_id = $1 AND category_id = $2
+		`, t.productID, t.categoryID, productStatusValues["deleted"])
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+}
+
+// hardDelete removes products with their tags, subcategories and promo; downloads are kept as history
+func (c *productChurn) hardDelete(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	return c.inTx(db, rng, batch, func(tx *sql.Tx, t churnTarget) (int64, error) {
+		for _, query := range []string{
+			"DELETE FROM product_tag WHERE product_id = $1",
+			"DELETE FROM product_product_category WHERE product_id = $1",
+			"DELETE FROM product_promo WHERE product_id = $1",
+		} {
+			if _, err := tx.Exec(query, t.productID); err != nil {
+				return 0, err
+			}
+		}
+
+		result, err := tx.Exec("DELETE FROM product WHERE product_id = $1 AND category_id = $2", t.productID, t.categoryID)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+}
+
+// tableBloat is the state of a table and its partitions in pg_stat_user_tables
+type tableBloat struct {
+	liveTuples   int64
+	deadTuples   int64
+	tableBytes   int64
+	indexBytes   int64
+	autovacuums  int64
+	autoanalyzes int64
+}
+
+// tableBloatSnapshot sums the statistics of each table over all of its partitions
+func tableBloatSnapshot(db *sql.DB, tables []string) (map[string]tableBloat, error) {
+	snapshot := make(map[string]tableBloat, len(tables))
+	for _, table := range tables {
+		var b tableBloat
+		err := db.QueryRow(`
+			SELECT COALESCE(SUM(s.n_live_tup), 0),
+			       COALESCE(SUM(s.n_dead_tup), 0),
+			       COALESCE(SUM(pg_table_size(s.relid)), 0),
+			       COALESCE(SUM(pg_indexes_size(s.relid)), 0),
+			       COALESCE(SUM(s.autovacuum_count), 0),
+			       COALESCE(SUM(s.autoanalyze_count), 0)
+			FROM pg_stat_user_tables s
+			WHERE s.relid IN (SELECT relid FROM pg_partition_tree($1::regclass))
+		`, table).Scan(&b.liveTuples, &b.deadTuples, &b.tableBytes, &b.indexBytes, &b.autovacuums, &b.autoanalyzes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read statistics of %s: %w", table, err)
+		}
+		snapshot[table] = b
+	}
+	return snapshot, nil
+}
+
+// LiveTuples returns the estimated number of rows of each table from pg_stat_user_tables, partitions included
+func LiveTuples(db *sql.DB, tables []string) (map[string]int64, error) {
+	snapshot, err := tableBloatSnapshot(db, tables)
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]int64, len(snapshot))
+	for table, b := range snapshot {
+		live[table] = b.liveTuples
+	}
+	return live, nil
+}
+
+// printTableBloat prints the statistics before and after the workload
+func printTableBloat(tables []string, before, after map[string]tableBloat) {
+	// The statistics collector reports with a small delay, the last operations may not be counted yet
+	fmt.Println("  Table statistics (before -> after, partitions included):")
+	for _, table := range tables {
+		b, a := before[table], after[table]
+		fmt.Printf("    %s\n", table)
+		fmt.Printf("      live tuples   %12d -> %12d\n", b.liveTuples, a.liveTuples)
+		fmt.Printf("      dead tuples   %12d -> %12d\n", b.deadTuples, a.deadTuples)
+		fmt.Printf("      table size    %12s -> %12s\n", formatBytes(b.tableBytes), formatBytes(a.tableBytes))
+		fmt.Printf("      index size    %12s -> %12s\n", formatBytes(b.indexBytes), formatBytes(a.indexBytes))
+		fmt.Printf("      autovacuums   %12d -> %12d\n", b.autovacuums, a.autovacuums)
+		fmt.Printf("      autoanalyzes  %12d -> %12d\n", b.autoanalyzes, a.autoanalyzes)
+	}
+	fmt.Println()
+}
+
+// formatBytes prints a size with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
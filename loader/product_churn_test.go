@@ -0,0 +1,42 @@
+package loader
+
+import "testing"
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{8 << 20, "8.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultProductChurnMix(t *testing.T) {
+	c := &productChurn{}
+	ops := map[string]churnOp{
+		"update":      c.update,
+		"retag":       c.retag,
+		"soft-delete": c.softDelete,
+		"hard-delete": c.hardDelete,
+	}
+	mix, err := parseChurnMix(DefaultProductChurnMix, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mix.names) != len(ops) {
+		t.Errorf("default mix covers %v, want all %d operations", mix.names, len(ops))
+	}
+	if _, err := parseChurnMix("update:1,activate:1", ops); err == nil {
+		t.Error("promo operation accepted in the product churn mix")
+	}
+}
@@ -0,0 +1,186 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DefaultProductStatuses = "published:0.92,draft:0.03,pending:0.02,unpublished:0.02,rejected:0.01"
+
+// productStatusValues maps product_status to the legacy status column, which
+// mv_product_not_available and product_status_not_published_idx are built on
+var productStatusValues = map[string]string{
+	"published":   "publish",
+	"draft":       "draft",
+	"pending":     "pending",
+	"rejected":    "rejected",
+	"unpublished": "unpublish",
+	"deleted":     "trash",
+}
+
+// categoryProductTypes is the product_type used for each top-level category when no explicit mix is given
+var categoryProductTypes = map[int64]string{
+	23:   "font",
+	546:  "bundle",
+	735:  "pattern",
+	1850: "3d-model",
+	2244: "3d-model",
+	2245: "3d-model",
+	2246: "pattern",
+}
+
+// weightedChoice picks names in proportion to their weights
+type weightedChoice struct {
+	names      []string
+	cumulative []float64
+}
+
+func newWeightedChoice(entries []ratioEntry) (weightedChoice, error) {
+	var c weightedChoice
+	sum := 0.0
+	for _, e := range entries {
+		if e.value < 0 {
+			return weightedChoice{}, fmt.Errorf("weight for %q must be >= 0, got %.4f", e.name, e.value)
+		}
+		sum += e.value
+		c.names = append(c.names, e.name)
+		c.cumulative = append(c.cumulative, sum)
+	}
+	if sum <= 0 {
+		return weightedChoice{}, fmt.Errorf("at least one weight must be > 0")
+	}
+	return c, nil
+}
+
+func (c weightedChoice) pick(rng *rand.Rand) string {
+	r := rng.Float64() * c.cumulative[len(c.cumulative)-1]
+	return c.names[sort.SearchFloat64s(c.cumulative, r)]
+}
+
+func (c weightedChoice) String() string {
+	total := c.cumulative[len(c.cumulative)-1]
+	parts := make([]string, len(c.names))
+	prev := 0.0
+	for i, name := range c.names {
+		parts[i] = fmt.Sprintf("%s %.1f%%", name, (c.cumulative[i]-prev)/total*100)
+		prev = c.cumulative[i]
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StatusConfig controls the product_type and product_status mix of generated products
+type StatusConfig struct {
+	Types    *weightedChoice // nil derives product_type from the category
+	Statuses weightedChoice
+}
+
+// ParseStatusConfig parses the "-product-types" and "-product-statuses" flag values
+func ParseStatusConfig(types, statuses string) (StatusConfig, error) {
+	var cfg StatusConfig
+
+	if strings.TrimSpace(types) != "" {
+		entries, err := parseRatioList(types)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid product types: %w", err)
+		}
+		for _, e := range entries {
+			if _, ok := payloadTemplates[e.name]; !ok {
+				return cfg, fmt.Errorf("unsupported product type %q", e.name)
+			}
+		}
+		choice, err := newWeightedChoice(entries)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid product types: %w", err)
+		}
+		cfg.Types = &choice
+	}
+
+	entries, err := parseRatioList(statuses)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid product statuses: %w", err)
+	}
+	for _, e := range entries {
+		if _, ok := productStatusValues[e.name]; !ok {
+			return cfg, fmt.Errorf("unsupported product status %q", e.name)
+		}
+	}
+	cfg.Statuses, err = newWeightedChoice(entries)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid product statuses: %w", err)
+	}
+	return cfg, nil
+}
+
+func (cfg StatusConfig) productType(rng *rand.Rand, categoryID int64) string {
+	if cfg.Types != nil {
+		return cfg.Types.pick(rng)
+	}
+	if productType, ok := categoryProductTypes[categoryID]; ok {
+		return productType
+	}
+	return "digital"
+}
+
+// productStatus returns a product_status and the matching status value
+func (cfg StatusConfig) productStatus(rng *rand.Rand) (string, string) {
+	productStatus := cfg.Statuses.pick(rng)
+	return productStatus, productStatusValues[productStatus]
+}
+
+// productLastUpdatedAt returns when a product was last edited: drafts have never been
+// submitted and keep a NULL last_updated_at, every other product was edited after creation
+func productLastUpdatedAt(rng *rand.Rand, productStatus string, createdAt, now time.Time) *time.Time {
+	if productStatus == "draft" {
+		return nil
+	}
+	if !now.After(createdAt) {
+		return &createdAt
+	}
+	lastUpdatedAt := createdAt.Add(time.Duration(rng.Int63n(int64(now.Sub(createdAt)) + 1)))
+	return &lastUpdatedAt
+}
+
+// verifyProductStatus checks that status agrees with product_status and prints the mix
+func verifyProductStatus(db *sql.DB, report *verifyReport) error {
+	rows, err := db.Query(`
+		SELECT product_type, product_status, COALESCE(status, ''), COUNT(*)
+		FROM product
+		GROUP BY product_type, product_status, status
+		ORDER BY COUNT(*) DESC
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to count product statuses: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatched int64
+	fmt.Println("  Products per type and status:")
+	for rows.Next() {
+		var productType, productStatus, status string
+		var count int64
+		if err := rows.Scan(&productType, &productStatus, &status, &count); err != nil {
+			return fmt.Errorf("failed to scan status count: %w", err)
+		}
+		fmt.Printf("    %-10s %-12s %-10s %d\n", productType, productStatus, status, count)
+		if expected, ok := productStatusValues[productStatus]; !ok || expected != status {
+			mismatched += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	report.check("status matches product_status", mismatched == 0, fmt.Sprintf("%d products with an unexpected status", mismatched))
+
+	var updatedBeforeCreated int64
+	err = db.QueryRow("SELECT COUNT(*) FROM product WHERE last_updated_at < created_at").Scan(&updatedBeforeCreated)
+	if err != nil {
+		return fmt.Errorf("failed to compare product timestamps: %w", err)
+	}
+	report.check("last_updated_at is not before created_at", updatedBeforeCreated == 0, fmt.Sprintf("%d products updated before they were created", updatedBeforeCreated))
+	return nil
+}
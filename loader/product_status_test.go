@@ -0,0 +1,95 @@
+package loader
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseStatusConfig(t *testing.T) {
+	tests := []struct {
+		types, statuses string
+		wantTypes       bool
+		wantErr         bool
+	}{
+		{"", DefaultProductStatuses, false, false},
+		{"font:1,digital:3", "published:1", true, false},
+		{"", "published:1,deleted:0", false, false},
+		{"vinyl:1", "published:1", false, true},
+		{"", "archived:1", false, true},
+		{"", "published:0,draft:0", false, true},
+		{"", "published:-1,draft:2", false, true},
+		{"", "", false, true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParseStatusConfig(tt.types, tt.statuses)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStatusConfig(%q, %q): got error %v, want error %t", tt.types, tt.statuses, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (cfg.Types != nil) != tt.wantTypes {
+			t.Errorf("ParseStatusConfig(%q, %q): explicit types %t, want %t", tt.types, tt.statuses, cfg.Types != nil, tt.wantTypes)
+		}
+	}
+}
+
+func TestProductStatusMix(t *testing.T) {
+	cfg, err := ParseStatusConfig("", "published:0.9,draft:0.1,rejected:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	const n = 20000
+	for i := 0; i < n; i++ {
+		productStatus, status := cfg.productStatus(rng)
+		if status != productStatusValues[productStatus] {
+			t.Fatalf("status %q for product_status %q", status, productStatus)
+		}
+		counts[productStatus]++
+	}
+	if counts["rejected"] != 0 || math.Abs(float64(counts["draft"])/n-0.1) > 0.01 {
+		t.Errorf("status mix %v, want 10%% drafts and no rejected products", counts)
+	}
+}
+
+func TestProductType(t *testing.T) {
+	cfg, err := ParseStatusConfig("", DefaultProductStatuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for categoryID, want := range map[int64]string{23: "font", 546: "bundle", 553: "digital", 2245: "3d-model"} {
+		if got := cfg.productType(rng, categoryID); got != want {
+			t.Errorf("category %d: product type %q, want %q", categoryID, got, want)
+		}
+	}
+
+	cfg, err = ParseStatusConfig("pattern:1", DefaultProductStatuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.productType(rng, 23); got != "pattern" {
+		t.Errorf("explicit mix: product type %q, want pattern", got)
+	}
+}
+
+func TestProductLastUpdatedAt(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := createdAt.Add(90 * 24 * time.Hour)
+
+	if got := productLastUpdatedAt(rng, "draft", createdAt, now); got != nil {
+		t.Errorf("draft last updated at %v, want NULL", got)
+	}
+	if got := productLastUpdatedAt(rng, "published", now, now); got == nil || !got.Equal(now) {
+		t.Errorf("product created now last updated at %v, want %v", got, now)
+	}
+	for i := 0; i < 1000; i++ {
+		got := productLastUpdatedAt(rng, "published", createdAt, now)
+		if got == nil || got.Before(createdAt) || got.After(now) {
+			t.Fatalf("last updated at %v, want between %v and %v", got, createdAt, now)
+		}
+	}
+}
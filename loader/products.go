@@ -0,0 +1,412 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tiny-cds-loader/writer"
+)
+
+// ProductConfig groups the generation settings of the products mode
+type ProductConfig struct {
+	Locales  LocaleConfig
+	Payload  PayloadConfig
+	Statuses StatusConfig
+	Authors  AuthorConfig
+	Pricing  PricingConfig
+	Timeline CatalogAgeConfig
+}
+
+// productColumns are the columns written for products
+var productColumns = []string{"product_id", "author_id", "category_id", "price_in_cents", "title", "slug", "description", "main_image",
+	"images", "assets", "product_type", "product_status", "metadata", "created_at", "last_updated_at", "status"}
+
+// ProductRow is one generated product with its subcategories and tags
+type ProductRow struct {
+	ID            int64
+	AuthorID      int64
+	CategoryID    int64 // Top-level category
+	PriceInCents  int64
+	Title         map[string]string // Localized, by locale
+	Slug          map[string]string
+	Description   map[string]string
+	MainImage     string // JSON documents
+	Images        string
+	Assets        string
+	Metadata      string
+	ProductType   string
+	ProductStatus string
+	Status        string
+	CreatedAt     time.Time
+	LastUpdatedAt *time.Time // nil for drafts, which were never edited
+	Subcategories []int64    // product_product_category
+	Tags          []int64    // product_tag, may repeat a tag
+}
+
+// ProductRows returns the product, product_product_category and product_tag rows of products, to be
+// written together
+func ProductRows(products []ProductRow) []writer.Rows {
+	product := writer.Rows{Table: "product", Columns: productColumns, Values: make([][]interface{}, 0, len(products))}
+	category := writer.Rows{Table: "product_product_category", Columns: []string{"product_id", "category_id"}}
+	tag := writer.Rows{Table: "product_tag", Columns: []string{"product_id", "tag_id", "product_created_at"}}
+
+	for _, p := range products {
+		var lastUpdatedAt interface{}
+		if p.LastUpdatedAt != nil {
+			lastUpdatedAt = *p.LastUpdatedAt
+		}
+		product.Values = append(product.Values, []interface{}{
+			p.ID,
+			p.AuthorID,
+			p.CategoryID,
+			p.PriceInCents,
+			mustJSON(p.Title),
+			mustJSON(p.Slug),
+			mustJSON(p.Description),
+			p.MainImage,
+			p.Images,
+			p.Assets,
+			p.ProductType,
+			p.ProductStatus,
+			p.Metadata,
+			p.CreatedAt,
+			lastUpdatedAt,
+			p.Status,
+		})
+		for _, subcatID := range p.Subcategories {
+			category.Values = append(category.Values, []interface{}{p.ID, subcatID})
+		}
+		for _, tagID := range p.Tags {
+			tag.Values = append(tag.Values, []interface{}{p.ID, tagID, p.CreatedAt})
+		}
+	}
+	return []writer.Rows{product, category, tag}
+}
+
+// ProductCatalog is what generated products reference
+type ProductCatalog struct {
+	Subcategories map[int64][]int64 // Subcategory IDs at any depth by top-level category_id
+	TagIDs        []int64           // Existing tag IDs, or nil when the tags are the dense range 1..MaxTagID
+	MaxTagID      int64
+	Newest        time.Time // created_at of the newest existing product, zero for an empty catalog
+}
+
+// loadProductCatalog reads the subcategories, tags and newest product of the database
+func loadProductCatalog(db *sql.DB) (ProductCatalog, error) {
+	var catalog ProductCatalog
+
+	// New products are created after the existing ones so created_at stays monotonic with product_id
+	newest, err := newestProductCreatedAt(db)
+	if err != nil {
+		return catalog, err
+	}
+	catalog.Newest = newest
+
+	fmt.Println("Loading subcategories from database...")
+	tree, err := loadCategoryTree(db)
+	if err != nil {
+		return catalog, err
+	}
+
+	// Subcategories at any depth are grouped under their top-level category
+	catalog.Subcategories = tree.subcategories()
+	var totalSubcategories int
+	for _, subs := range catalog.Subcategories {
+		totalSubcategories += len(subs)
+	}
+	fmt.Printf("Loaded %d subcategories\n", totalSubcategories)
+
+	fmt.Println("Loading tags from database...")
+	tags, err := loadTagSampler(db)
+	if err != nil {
+		return catalog, err
+	}
+	catalog.TagIDs, catalog.MaxTagID = tags.ids, tags.maxID
+	fmt.Printf("Loaded %d tags\n", tags.len())
+	return catalog, nil
+}
+
+// NewProductCatalog returns the catalog of generated categories and of the tags 1..maxTagID, for products
+// generated along with them instead of after they were written
+func NewProductCatalog(categories []CategoryRow, maxTagID int64) ProductCatalog {
+	return ProductCatalog{Subcategories: newCategoryTree(categories).subcategories(), MaxTagID: maxTagID}
+}
+
+// ProductGenerator generates products with IDs in a fixed range, so the timeline can spread their created_at
+type ProductGenerator struct {
+	cfg             ProductConfig
+	subcategoryList map[int64][]int64
+	tags            *idSampler
+	authors         *authorModel
+	timeline        *productTimeline
+	categoryWeights []float64
+	now             time.Time
+}
+
+// NewProductGenerator returns a generator for the products startID to startID+count-1, created between the
+// newest product of the catalog and now
+func NewProductGenerator(cfg ProductConfig, catalog ProductCatalog, startID int64, count int, now time.Time) (*ProductGenerator, error) {
+	if err := cfg.Payload.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Authors.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Timeline.validate(); err != nil {
+		return nil, err
+	}
+
+	totalSubcategories := 0
+	for _, subs := range catalog.Subcategories {
+		totalSubcategories += len(subs)
+	}
+	if totalSubcategories == 0 {
+		return nil, fmt.Errorf("no subcategories found - please import subcategories first")
+	}
+	if len(catalog.TagIDs) == 0 && catalog.MaxTagID <= 0 {
+		return nil, fmt.Errorf("no tags found in database - please import tags first")
+	}
+
+	return &ProductGenerator{
+		cfg:             cfg,
+		subcategoryList: catalog.Subcategories,
+		tags:            &idSampler{ids: catalog.TagIDs, maxID: catalog.MaxTagID},
+		authors:         buildAuthorModel(cfg.Authors),
+		timeline:        newProductTimeline(cfg.Timeline, startID, count, catalog.Newest, now),
+		categoryWeights: buildCategoryWeights(),
+		now:             now,
+	}, nil
+}
+
+// Generate returns count products numbered from startID
+func (g *ProductGenerator) Generate(rng *rand.Rand, startID int64, count int) []ProductRow {
+	products := make([]ProductRow, 0, count)
+	for i := 0; i < count; i++ {
+		productID := startID + int64(i)
+		categoryID := selectCategoryByWeight(rng, g.categoryWeights)
+		subcategoryIDs := selectSubcategories(rng, categoryID, g.subcategoryList)
+		tagIDs := selectRandomTags(rng, g.tags)
+		createdAt := g.timeline.createdAt(productID)
+
+		// Generate mock data
+		productType := g.cfg.Statuses.productType(rng, categoryID)
+		productStatus, status := g.cfg.Statuses.productStatus(rng)
+		localized := generateLocalizedProduct(rng, g.cfg.Locales, productID)
+		payload := generateProductPayload(rng, g.cfg.Payload, productID, productType, localized.title[g.cfg.Locales.defaultLocale()])
+
+		p := ProductRow{
+			ID:            productID,
+			AuthorID:      g.authors.pick(rng, categoryID),
+			CategoryID:    categoryID,
+			PriceInCents:  g.cfg.Pricing.price(rng, categoryID),
+			Title:         localized.title,
+			Slug:          localized.slug,
+			Description:   localized.description,
+			MainImage:     payload.mainImage,
+			Images:        payload.images,
+			Assets:        payload.assets,
+			Metadata:      payload.metadata,
+			ProductType:   productType,
+			ProductStatus: productStatus,
+			Status:        status,
+			CreatedAt:     createdAt,
+			LastUpdatedAt: productLastUpdatedAt(rng, productStatus, createdAt, g.now),
+			Subcategories: subcategoryIDs,
+			Tags:          tagIDs,
+		}
+		products = append(products, p)
+	}
+	return products
+}
+
+// ImportProducts appends productCount products after the existing ones
+func (l *Loader) ImportProducts(productCount int, cfg ProductConfig) error {
+	fmt.Print("\n=== Importing Products ===\n\n")
+	fmt.Printf("Importing %d products using %d workers...\n", productCount, Workers)
+	fmt.Printf("Locales: %s\n", cfg.Locales)
+	fmt.Printf("Payloads: %.1f images, %.1f assets on average, metadata scale %.1f\n", cfg.Payload.ImagesMean, cfg.Payload.AssetsMean, cfg.Payload.MetadataScale)
+
+	if cfg.Statuses.Types != nil {
+		fmt.Printf("Product types: %s\n", cfg.Statuses.Types)
+	} else {
+		fmt.Println("Product types: derived from category")
+	}
+	fmt.Printf("Product statuses: %s\n", cfg.Statuses.Statuses)
+
+	fmt.Printf("Prices: per-category log-normal models, %.0f%% charm pricing\n", cfg.Pricing.CharmRate*100)
+	fmt.Printf("Authors: %d (Pareto alpha %.2f, %.0f%% cross-category)\n", cfg.Authors.Count, cfg.Authors.Alpha, cfg.Authors.CrossCategoryRate*100)
+	fmt.Printf("Catalog age: %.1f years, %.0f%% yearly growth\n", cfg.Timeline.Years, cfg.Timeline.Growth*100)
+
+	// Get the starting product ID by finding the max existing product_id
+	startID, err := l.dialect.MaxID(l.db, "product", "product_id")
+	if err != nil {
+		return fmt.Errorf("failed to get starting product ID: %w", err)
+	}
+	startID++ // Start from next available ID
+
+	catalog, err := loadProductCatalog(l.db)
+	if err != nil {
+		return err
+	}
+	generator, err := NewProductGenerator(cfg, catalog, startID, productCount, time.Now())
+	if err != nil {
+		return err
+	}
+	timeline := generator.timeline
+	fmt.Printf("Spreading created_at from %s to %s\n", timeline.start.Format("2006-01-02"), timeline.start.Add(timeline.span).Format("2006-01-02"))
+
+	bar := l.newProgressBar(productCount, "Products")
+
+	// Create work channel
+	type productBatch struct {
+		startID int64
+		count   int
+	}
+
+	jobs := make(chan productBatch, 100)
+	errors := make(chan error, 1000) // Larger buffer for errors
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	totalInserted := 0
+	var firstError error
+
+	// Error collector goroutine
+	var errorWg sync.WaitGroup
+	errorWg.Add(1)
+	go func() {
+		defer errorWg.Done()
+		for err := range errors {
+			if err != nil && firstError == nil {
+				mu.Lock()
+				if firstError == nil {
+					firstError = err
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	// Start worker goroutines
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			rng := l.newRand(int64(workerID) * 1000)
+
+			for batch := range jobs {
+				// Products and their relations are written in one transaction
+				products := generator.Generate(rng, batch.startID, batch.count)
+				inserted, err := l.sink.Write(ProductRows(products)...)
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: %w", workerID, err)
+					continue
+				}
+
+				mu.Lock()
+				totalInserted += int(inserted[0]) // The product rows, the others are their relations
+				bar.Add(batch.count)
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	// Send jobs to workers
+	go func() {
+		currentID := startID
+		remaining := productCount
+
+		for remaining > 0 {
+			count := productBatchSize
+			if remaining < productBatchSize {
+				count = remaining
+			}
+
+			jobs <- productBatch{startID: currentID, count: count}
+			currentID += int64(count)
+			remaining -= count
+		}
+		close(jobs)
+	}()
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(errors)
+
+	// Wait for error collector to finish
+	errorWg.Wait()
+
+	// Check if there was an error
+	if firstError != nil {
+		return firstError
+	}
+
+	fmt.Printf("\n  ✓ Inserted: %d products\n", totalInserted)
+	// pg_column_size has no SQLite counterpart
+	if l.dialect.Driver() == "postgres" {
+		if err := reportProductRowWidth(l.db, startID, startID+int64(productCount)-1); err != nil {
+			return err
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func buildCategoryWeights() []float64 {
+	weights := make([]float64, len(categories))
+	sum := 0.0
+	for i, cat := range categories {
+		sum += cat.Percentage
+		weights[i] = sum
+	}
+	return weights
+}
+
+func selectCategoryByWeight(rng *rand.Rand, weights []float64) int64 {
+	r := rng.Float64()
+	for i, w := range weights {
+		if r <= w {
+			return categories[i].ID
+		}
+	}
+	return categories[len(categories)-1].ID
+}
+
+func selectSubcategories(rng *rand.Rand, categoryID int64, subcategoryList map[int64][]int64) []int64 {
+	subcats, exists := subcategoryList[categoryID]
+	if !exists || len(subcats) == 0 {
+		return []int64{}
+	}
+
+	// Select 1-3 random subcategories for this category
+	numSubs := rng.Intn(3) + 1
+	if numSubs > len(subcats) {
+		numSubs = len(subcats)
+	}
+
+	// Shuffle and pick first numSubs
+	shuffled := make([]int64, len(subcats))
+	copy(shuffled, subcats)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled[:numSubs]
+}
+
+func selectRandomTags(rng *rand.Rand, tags *idSampler) []int64 {
+	// Generate ~25 tags per product (with some variance)
+	numTags := avgTagsPerProduct + rng.Intn(11) - 5 // 20-30 tags
+	if numTags < 1 {
+		numTags = 1
+	}
+
+	tagIDs := make([]int64, numTags)
+	for i := 0; i < numTags; i++ {
+		tagIDs[i] = tags.pick(rng)
+	}
+	return tagIDs
+}
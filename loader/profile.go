@@ -0,0 +1,120 @@
+package loader
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// datasetProfile gives the size of a dataset at scale 1; ratios are per product and don't change with the scale
+type datasetProfile struct {
+	name          string
+	description   string
+	products      int
+	subcategories int // Grows with the square root of the scale, catalogs get deeper rather than wider
+	tags          int
+	authors       int
+	promoRate     float64 // Promos per product
+	downloadRate  float64 // Downloads per product
+	hotTagShare   float64 // Share of products carrying the hot tag
+}
+
+// datasetProfiles are the built-in profiles, smallest first
+var datasetProfiles = []datasetProfile{
+	{name: "tiny", description: "unit tests", products: 500, subcategories: 20, tags: 2000, authors: 25, promoRate: 0.1, downloadRate: 10, hotTagShare: 0.2},
+	{name: "dev", description: "local development", products: 50000, subcategories: 200, tags: 100000, authors: 1000, promoRate: 0.05, downloadRate: 20, hotTagShare: 0.1},
+	{name: "perf", description: "performance testing", products: 2000000, subcategories: 1000, tags: 2000000, authors: 20000, promoRate: 0.03, downloadRate: 20, hotTagShare: 0.05},
+	{name: "prod-like", description: "production sized", products: 20000000, subcategories: 2000, tags: DefaultTagCount, authors: 100000, promoRate: 0.02, downloadRate: 25, hotTagShare: 0.02},
+}
+
+// DatasetPlan is the number of rows every mode writes, derived from a profile and a scale
+type DatasetPlan struct {
+	Profile       string
+	Description   string
+	Scale         float64
+	Subcategories int
+	Tags          int
+	Products      int
+	Authors       int
+	Promos        int
+	Downloads     int
+	HotTagID      int64
+	HotTag        int // Products attached to HotTagID
+}
+
+// PlanModes is the import order of the "all" mode
+var PlanModes = []string{"categories", "subcategories", "tags", "products", "promos", "downloads", "hugetag", "tag-relations", "bundles"}
+
+// NewDatasetPlan derives every count of a profile at the given scale
+func NewDatasetPlan(profile string, scale float64) (DatasetPlan, error) {
+	if scale <= 0 {
+		return DatasetPlan{}, fmt.Errorf("scale must be > 0, got %g", scale)
+	}
+
+	var p *datasetProfile
+	names := make([]string, len(datasetProfiles))
+	for i := range datasetProfiles {
+		names[i] = datasetProfiles[i].name
+		if datasetProfiles[i].name == profile {
+			p = &datasetProfiles[i]
+		}
+	}
+	if p == nil {
+		return DatasetPlan{}, fmt.Errorf("unknown profile %q, expected one of %s", profile, strings.Join(names, ", "))
+	}
+
+	scaled := func(n float64) int {
+		return int(math.Max(1, math.Round(n)))
+	}
+	plan := DatasetPlan{
+		Profile:       p.name,
+		Description:   p.description,
+		Scale:         scale,
+		Subcategories: scaled(float64(p.subcategories) * math.Sqrt(scale)),
+		Tags:          scaled(float64(p.tags) * scale),
+		Products:      scaled(float64(p.products) * scale),
+		Authors:       scaled(float64(p.authors) * scale),
+	}
+	plan.Promos = scaled(float64(plan.Products) * p.promoRate)
+	plan.Downloads = scaled(float64(plan.Products) * p.downloadRate)
+	plan.HotTag = scaled(float64(plan.Products) * p.hotTagShare)
+
+	// Small catalogs don't have tag 12345, their hot tag is the most popular one instead
+	plan.HotTagID = HugeTagID
+	if int64(plan.Tags) < HugeTagID {
+		plan.HotTagID = 1
+	}
+	return plan, nil
+}
+
+// Count returns the number of records mode writes; 0 for modes without a count
+func (p DatasetPlan) Count(mode string) int {
+	switch mode {
+	case "subcategories":
+		return p.Subcategories
+	case "tags":
+		return p.Tags
+	case "products":
+		return p.Products
+	case "promos":
+		return p.Promos
+	case "downloads":
+		return p.Downloads
+	case "hugetag":
+		return p.HotTag
+	}
+	return 0
+}
+
+// Print shows the plan before anything is written
+func (p DatasetPlan) Print() {
+	fmt.Printf("\n=== Dataset Plan: %s (%s), scale %g ===\n\n", p.Profile, p.Description, p.Scale)
+	fmt.Printf("  %-15s %12d\n", "categories", len(categories))
+	fmt.Printf("  %-15s %12d\n", "subcategories", p.Subcategories)
+	fmt.Printf("  %-15s %12d\n", "tags", p.Tags)
+	fmt.Printf("  %-15s %12d  (%d authors)\n", "products", p.Products, p.Authors)
+	fmt.Printf("  %-15s %12d  (%.1f%% of products)\n", "promos", p.Promos, float64(p.Promos)/float64(p.Products)*100)
+	fmt.Printf("  %-15s %12d  (%.1f per product)\n", "downloads", p.Downloads, float64(p.Downloads)/float64(p.Products))
+	fmt.Printf("  %-15s %12d  (products with tag %d)\n", "hot tag", p.HotTag, p.HotTagID)
+	fmt.Println()
+}
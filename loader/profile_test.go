@@ -0,0 +1,59 @@
+package loader
+
+import "testing"
+
+func TestNewDatasetPlan(t *testing.T) {
+	tests := []struct {
+		profile string
+		scale   float64
+		want    DatasetPlan
+		wantErr bool
+	}{
+		{"tiny", 1, DatasetPlan{Subcategories: 20, Tags: 2000, Products: 500, Authors: 25, Promos: 50, Downloads: 5000, HotTagID: 1, HotTag: 100}, false},
+		{"tiny", 4, DatasetPlan{Subcategories: 40, Tags: 8000, Products: 2000, Authors: 100, Promos: 200, Downloads: 20000, HotTagID: 1, HotTag: 400}, false},
+		{"tiny", 0.001, DatasetPlan{Subcategories: 1, Tags: 2, Products: 1, Authors: 1, Promos: 1, Downloads: 10, HotTagID: 1, HotTag: 1}, false},
+		{"dev", 1, DatasetPlan{Subcategories: 200, Tags: 100000, Products: 50000, Authors: 1000, Promos: 2500, Downloads: 1000000, HotTagID: HugeTagID, HotTag: 5000}, false},
+		{"huge", 1, DatasetPlan{}, true},
+		{"dev", 0, DatasetPlan{}, true},
+		{"dev", -1, DatasetPlan{}, true},
+	}
+	for _, tt := range tests {
+		plan, err := NewDatasetPlan(tt.profile, tt.scale)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewDatasetPlan(%q, %g): got error %v, want error %t", tt.profile, tt.scale, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		tt.want.Profile, tt.want.Description, tt.want.Scale = plan.Profile, plan.Description, tt.scale
+		if plan != tt.want {
+			t.Errorf("NewDatasetPlan(%q, %g) = %+v, want %+v", tt.profile, tt.scale, plan, tt.want)
+		}
+	}
+}
+
+func TestDatasetPlanCount(t *testing.T) {
+	plan, err := NewDatasetPlan("tiny", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		mode string
+		want int
+	}{
+		{"subcategories", plan.Subcategories},
+		{"tags", plan.Tags},
+		{"products", plan.Products},
+		{"promos", plan.Promos},
+		{"downloads", plan.Downloads},
+		{"hugetag", plan.HotTag},
+		{"categories", 0},
+		{"bundles", 0},
+	}
+	for _, tt := range tests {
+		if got := plan.Count(tt.mode); got != tt.want {
+			t.Errorf("count(%q) = %d, want %d", tt.mode, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultPromoChurnMix = "activate:0.2,expire:0.2,pause:0.1,resume:0.1,extend:0.2,delete:0.1,create:0.1"
+
+// promoChurn mutates product_promo the way production does: status transitions, extensions,
+// deletions of old promos and new promos
+type promoChurn struct {
+	cfg         PromoConfig
+	nextPromoID atomic.Int64
+	minProduct  int64
+	maxProduct  int64
+}
+
+// ImportPromoChurn runs the promo lifecycle workload
+func (l *Loader) ImportPromoChurn(churn ChurnConfig, mixSpec string, cfg PromoConfig) error {
+	fmt.Print("\n=== Promo Churn ===\n\n")
+
+	if err := churn.validate(); err != nil {
+		return err
+	}
+
+	// New promos start between now and the lookahead, so they are mostly scheduled
+	cfg.Lookback = 0
+	c := &promoChurn{cfg: cfg}
+
+	ops := map[string]churnOp{
+		"activate": c.activate,
+		"expire":   c.expire,
+		"pause":    c.pause,
+		"resume":   c.resume,
+		"extend":   c.extend,
+		"delete":   c.delete,
+		"create":   c.create,
+	}
+	mix, err := parseChurnMix(mixSpec, ops)
+	if err != nil {
+		return err
+	}
+
+	var maxPromoID int64
+	err = l.db.QueryRow("SELECT COALESCE(MAX(product_promo_id), 0) FROM product_promo").Scan(&maxPromoID)
+	if err != nil {
+		return fmt.Errorf("failed to get starting promo ID: %w", err)
+	}
+	c.nextPromoID.Store(maxPromoID)
+
+	// The product ID range only positions random scans, every touched row is read from the tables
+	err = l.db.QueryRow("SELECT COALESCE(MIN(product_id), 0), COALESCE(MAX(product_id), 0) FROM product").Scan(&c.minProduct, &c.maxProduct)
+	if err != nil {
+		return fmt.Errorf("failed to get product ID range: %w", err)
+	}
+	if c.maxProduct == 0 {
+		return fmt.Errorf("no products found in database - please import products first")
+	}
+
+	rate := "unlimited"
+	if churn.Rate > 0 {
+		rate = fmt.Sprintf("%.0f ops/s", churn.Rate)
+	}
+	fmt.Printf("Running for %s at %s, %d row(s) per operation, %d workers\n", churn.Duration, rate, churn.Batch, Workers)
+	fmt.Printf("Operations: %s\n\n", mix)
+
+	return l.runChurn(churn, mix, ops)
+}
+
+// pivot returns a random product ID to start a scan from, so workers spread over the table
+func (c *promoChurn) pivot(rng *rand.Rand) int64 {
+	return c.minProduct + rng.Int63n(c.maxProduct-c.minProduct+1)
+}
+
+// updateFromPivot runs an UPDATE or DELETE whose $1 is a scan start and $2 a row limit. When nothing
+// matches after the pivot it retries from the start of the table.
+func (c *promoChurn) updateFromPivot(db *sql.DB, rng *rand.Rand, batch int, query string, args ...interface{}) (int64, error) {
+	for _, start := range []int64{c.pivot(rng), c.minProduct} {
+		result, err := db.Exec(query, append([]interface{}{start, batch}, args...)...)
+		if err != nil {
+			return 0, err
+		}
+		rows, _ := result.RowsAffected()
+		if rows > 0 || start == c.minProduct {
+			return rows, nil
+		}
+	}
+	return 0, nil
+}
+
+// activate starts the scheduled promos that are due first; promos started early get their created_at moved to now
+func (c *promoChurn) activate(db *sql.DB, _ *rand.Rand, batch int) (int64, error) {
+	result, err := db.Exec(`
+		UPDATE product_promo
+		SET status = 'active', created_at = LEAST(created_at, NOW()), last_updated_at = NOW()
+		WHERE product_id IN (
+			SELECT product_id FROM product_promo
+			WHERE status = 'scheduled'
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`, batch)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// expire ends the running promos closest to their expiration; promos ended early get their expires_at moved to now
+func (c *promoChurn) expire(db *sql.DB, _ *rand.Rand, batch int) (int64, error) {
+	result, err := db.Exec(`
+		UPDATE product_promo
+		SET status = 'expired',
+		    expires_at = GREATEST(LEAST(expires_at, NOW()), created_at + INTERVAL '1 second'),
+		    last_updated_at = NOW()
+		WHERE product_id IN (
+			SELECT product_id FROM product_promo
+			WHERE status IN ('active', 'paused')
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`, batch)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// pause pauses random active promos
+func (c *promoChurn) pause(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	return c.updateFromPivot(db, rng, batch, `
+		UPDATE product_promo
+		SET status = 'paused', last_updated_at = NOW()
+		WHERE product_id IN (
+			SELECT product_id FROM product_promo
+			WHERE status = 'active' AND product_id >= $1
+			ORDER BY product_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`)
+}
+
+// resume resumes random paused promos, or expires them when they ran out while paused
+func (c *promoChurn) resume(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	return c.updateFromPivot(db, rng, batch, `
+		UPDATE product_promo
+		SET status = CASE WHEN expires_at > NOW() THEN 'active' ELSE 'expired' END, last_updated_at = NOW()
+		WHERE product_id IN (
+			SELECT product_id FROM product_promo
+			WHERE status = 'paused' AND product_id >= $1
+			ORDER BY product_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`)
+}
+
+// extend pushes the expiration of random promos that haven't ended by 1 to 14 days
+func (c *promoChurn) extend(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	return c.updateFromPivot(db, rng, batch, `
+		UPDATE product_promo
+		SET expires_at = expires_at + $3 * INTERVAL '1 day', last_updated_at = NOW()
+		WHERE product_id IN (
+			SELECT product_id FROM product_promo
+			WHERE status IN ('scheduled', 'active', 'paused') AND product_id >= $1
+			ORDER BY product_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`, rng.Intn(14)+1)
+}
+
+// delete removes the promos that expired the longest ago
+func (c *promoChurn) delete(db *sql.DB, _ *rand.Rand, batch int) (int64, error) {
+	result, err := db.Exec(`
+		DELETE FROM product_promo
+		WHERE product_id IN (
+			SELECT product_id FROM product_promo
+			WHERE status = 'expired'
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`, batch)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// create gives new promos to random products without one
+func (c *promoChurn) create(db *sql.DB, rng *rand.Rand, batch int) (int64, error) {
+	query := `
+		SELECT p.product_id, p.created_at
+		FROM product p
+		WHERE p.product_id >= $1
+		  AND NOT EXISTS (SELECT 1 FROM product_promo pp WHERE pp.product_id = p.product_id)`
+	if !c.cfg.IncludeUnpublished {
+		query += " AND p.product_status = 'published'"
+	}
+	query += " ORDER BY p.product_id LIMIT $2"
+
+	type target struct {
+		productID int64
+		createdAt time.Time
+	}
+	var targets []target
+	for _, start := range []int64{c.pivot(rng), c.minProduct} {
+		rows, err := db.Query(query, start, batch)
+		if err != nil {
+			return 0, err
+		}
+		for rows.Next() {
+			var t target
+			if err := rows.Scan(&t.productID, &t.createdAt); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			targets = append(targets, t)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		if len(targets) > 0 {
+			break
+		}
+	}
+	if len(targets) == 0 {
+		return 0, nil
+	}
+
+	cfg := c.cfg
+	cfg.Reference = time.Now()
+
+	var query2 strings.Builder
+	query2.WriteString("INSERT INTO product_promo (product_promo_id, product_id, promo_type, status, expires_at, created_at, last_updated_at) VALUES ")
+	args := make([]interface{}, 0, len(targets)*7)
+	for i, t := range targets {
+		if i > 0 {
+			query2.WriteString(", ")
+		}
+		promo := cfg.generatePromo(rng, t.createdAt)
+		argPos := i*7 + 1
+		query2.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			argPos, argPos+1, argPos+2, argPos+3, argPos+4, argPos+5, argPos+6))
+		args = append(args, c.nextPromoID.Add(1), t.productID, promo.promoType, promo.status, promo.expiresAt, promo.createdAt, promo.lastUpdatedAt)
+	}
+	query2.WriteString(" ON CONFLICT (product_id) DO NOTHING")
+
+	result, err := db.Exec(query2.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
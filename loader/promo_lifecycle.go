@@ -0,0 +1,226 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPromoDurations = "discount:3d-30d,featured:7d-30d,bundle:7d-60d,seasonal:14d-42d,flash-sale:2h-48h"
+	DefaultPromoLookback  = 60 // Days before the reference time the oldest promos start
+	DefaultPromoLookahead = 14 // Days after the reference time the last scheduled promos start
+	DefaultPromoPaused    = 0.05
+)
+
+// promoDuration is the range of lengths of one promo_type
+type promoDuration struct {
+	promoType string
+	min, max  time.Duration
+}
+
+// PromoConfig controls the promo_type, dates and status of generated promos.
+// A promo runs from created_at to expires_at and its status follows from the reference time.
+type PromoConfig struct {
+	Reference          time.Time
+	Durations          []promoDuration
+	Lookback           time.Duration // Earliest promo start before the reference time
+	Lookahead          time.Duration // Latest promo start after the reference time
+	PausedRate         float64       // Share of running promos that are paused
+	IncludeUnpublished bool          // Also target products that are not published
+}
+
+// ParsePromoConfig parses the promo flags; an empty reference means now
+func ParsePromoConfig(reference, durations string, lookbackDays, lookaheadDays int, pausedRate float64, includeUnpublished bool) (PromoConfig, error) {
+	cfg := PromoConfig{
+		Reference:          time.Now(),
+		Lookback:           time.Duration(lookbackDays) * 24 * time.Hour,
+		Lookahead:          time.Duration(lookaheadDays) * 24 * time.Hour,
+		PausedRate:         pausedRate,
+		IncludeUnpublished: includeUnpublished,
+	}
+	if reference != "" {
+		t, err := parseReferenceTime(reference)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Reference = t
+	}
+	if lookbackDays < 0 || lookaheadDays < 0 {
+		return cfg, fmt.Errorf("promo lookback and lookahead must be >= 0 days")
+	}
+	if pausedRate < 0 || pausedRate > 1 {
+		return cfg, fmt.Errorf("paused promo rate must be between 0 and 1, got %.2f", pausedRate)
+	}
+
+	for _, item := range strings.Split(durations, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		promoType, spec, ok := strings.Cut(item, ":")
+		minSpec, maxSpec, ok2 := strings.Cut(spec, "-")
+		if !ok || !ok2 {
+			return cfg, fmt.Errorf("invalid promo duration %q, expected type:min-max such as flash-sale:2h-48h", item)
+		}
+		min, err := parseDayDuration(minSpec)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid promo duration %q: %w", item, err)
+		}
+		max, err := parseDayDuration(maxSpec)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid promo duration %q: %w", item, err)
+		}
+		if min <= 0 || max < min {
+			return cfg, fmt.Errorf("invalid promo duration %q, expected 0 < min <= max", item)
+		}
+		cfg.Durations = append(cfg.Durations, promoDuration{promoType: strings.TrimSpace(promoType), min: min, max: max})
+	}
+	if len(cfg.Durations) == 0 {
+		return cfg, fmt.Errorf("at least one promo type duration is required")
+	}
+	return cfg, nil
+}
+
+// parseReferenceTime accepts RFC 3339 timestamps and plain dates
+func parseReferenceTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid reference time %q, expected RFC 3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// parseDayDuration parses a Go duration, also accepting "d" (days) and "w" (weeks) suffixes
+func parseDayDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			value, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(value * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// formatDayDuration prints whole days as "3d" and anything else as hours such as "36h"
+func formatDayDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%gh", d.Hours())
+}
+
+func (cfg PromoConfig) String() string {
+	parts := make([]string, len(cfg.Durations))
+	for i, d := range cfg.Durations {
+		parts[i] = fmt.Sprintf("%s %s-%s", d.promoType, formatDayDuration(d.min), formatDayDuration(d.max))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// promoStatusAt derives the status of a promo from its dates; paused only applies to running promos
+func promoStatusAt(createdAt, expiresAt, reference time.Time, paused bool) string {
+	switch {
+	case createdAt.After(reference):
+		return "scheduled"
+	case !expiresAt.After(reference):
+		return "expired"
+	case paused:
+		return "paused"
+	default:
+		return "active"
+	}
+}
+
+// generatedPromo is one product_promo row
+type generatedPromo struct {
+	promoType     string
+	status        string
+	createdAt     time.Time
+	expiresAt     time.Time
+	lastUpdatedAt *time.Time // nil while scheduled
+}
+
+// generatePromo draws a promo type, a start between the lookback and the lookahead (never before the
+// product was created) and a duration for the type, then derives the status at the reference time
+func (cfg PromoConfig) generatePromo(rng *rand.Rand, productCreatedAt time.Time) generatedPromo {
+	d := cfg.Durations[rng.Intn(len(cfg.Durations))]
+	duration := d.min
+	if d.max > d.min {
+		duration += time.Duration(rng.Int63n(int64(d.max - d.min)))
+	}
+
+	earliest := cfg.Reference.Add(-cfg.Lookback)
+	createdAt := earliest.Add(time.Duration(rng.Int63n(int64(cfg.Lookback+cfg.Lookahead) + 1)))
+	if createdAt.Before(productCreatedAt) {
+		createdAt = productCreatedAt
+	}
+	expiresAt := createdAt.Add(duration)
+
+	p := generatedPromo{
+		promoType: d.promoType,
+		status:    promoStatusAt(createdAt, expiresAt, cfg.Reference, rng.Float64() < cfg.PausedRate),
+		createdAt: createdAt,
+		expiresAt: expiresAt,
+	}
+
+	// last_updated_at is the last status transition: none yet for scheduled promos
+	switch p.status {
+	case "active":
+		p.lastUpdatedAt = &createdAt
+	case "paused":
+		pausedAt := createdAt.Add(time.Duration(rng.Int63n(int64(cfg.Reference.Sub(createdAt)) + 1)))
+		p.lastUpdatedAt = &pausedAt
+	case "expired":
+		p.lastUpdatedAt = &expiresAt
+	}
+	return p
+}
+
+// verifyPromos checks promo dates and that statuses agree with them as of now
+func verifyPromos(db *sql.DB, report *verifyReport) error {
+	var inverted int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM product_promo WHERE expires_at <= created_at").Scan(&inverted); err != nil {
+		return fmt.Errorf("failed to check promo dates: %w", err)
+	}
+	report.check("expires_at is after created_at", inverted == 0, fmt.Sprintf("%d promos expiring before they start", inverted))
+
+	rows, err := db.Query(`
+		SELECT status,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE CASE status
+		           WHEN 'scheduled' THEN created_at <= NOW()
+		           WHEN 'expired' THEN expires_at > NOW()
+		           ELSE created_at > NOW() OR expires_at <= NOW()
+		       END)
+		FROM product_promo
+		GROUP BY status
+		ORDER BY COUNT(*) DESC
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to count promo statuses: %w", err)
+	}
+	defer rows.Close()
+
+	// Statuses drift as time passes without promo updates, so stale promos are only reported
+	fmt.Println("  Promos per status (stale = dates disagree with the status as of now):")
+	for rows.Next() {
+		var status string
+		var count, stale int64
+		if err := rows.Scan(&status, &count, &stale); err != nil {
+			return fmt.Errorf("failed to scan promo status: %w", err)
+		}
+		fmt.Printf("    %-10s %10d  %d stale\n", status, count, stale)
+	}
+	return rows.Err()
+}
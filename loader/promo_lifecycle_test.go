@@ -0,0 +1,185 @@
+package loader
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestParseDayDuration(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"3d", 72 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{" 48h ", 48 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"d", 0, true},
+		{"xd", 0, true},
+		{"3", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDayDuration(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDayDuration(%q): got error %v, want error %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDayDuration(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDayDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{72 * time.Hour, "3d"},
+		{36 * time.Hour, "36h"},
+		{90 * time.Minute, "1.5h"},
+	}
+	for _, tt := range tests {
+		if got := formatDayDuration(tt.d); got != tt.want {
+			t.Errorf("formatDayDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestParseReferenceTime(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2026-03-01T12:30:00Z", time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC), false},
+		{"2026-03-01 12:30", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseReferenceTime(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseReferenceTime(%q): got error %v, want error %t", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseReferenceTime(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParsePromoConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		durations string
+		lookback  int
+		lookahead int
+		paused    float64
+		wantTypes int
+		wantErr   bool
+	}{
+		{"defaults", "2026-03-01", DefaultPromoDurations, DefaultPromoLookback, DefaultPromoLookahead, DefaultPromoPaused, 5, false},
+		{"single type", "", "flash-sale:2h-48h", 0, 0, 0, 1, false},
+		{"empty entries skipped", "", "discount:3d-30d,, ", 1, 1, 0, 1, false},
+		{"no types", "", " ", 1, 1, 0, 0, true},
+		{"missing range", "", "discount:3d", 1, 1, 0, 0, true},
+		{"missing type", "", "3d-30d", 1, 1, 0, 0, true},
+		{"max below min", "", "discount:30d-3d", 1, 1, 0, 0, true},
+		{"zero min", "", "discount:0d-3d", 1, 1, 0, 0, true},
+		{"bad duration", "", "discount:3x-30d", 1, 1, 0, 0, true},
+		{"negative lookback", "", "discount:3d-30d", -1, 1, 0, 0, true},
+		{"paused above 1", "", "discount:3d-30d", 1, 1, 1.5, 0, true},
+		{"bad reference", "soon", "discount:3d-30d", 1, 1, 0, 0, true},
+	}
+	for _, tt := range tests {
+		cfg, err := ParsePromoConfig(tt.reference, tt.durations, tt.lookback, tt.lookahead, tt.paused, false)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && len(cfg.Durations) != tt.wantTypes {
+			t.Errorf("%s: got %d promo types, want %d", tt.name, len(cfg.Durations), tt.wantTypes)
+		}
+	}
+
+	cfg, err := ParsePromoConfig("2026-03-01", "flash-sale:2h-1.5d", 1, 1, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "flash-sale 2h-36h"; cfg.String() != want {
+		t.Errorf("String() = %q, want %q", cfg.String(), want)
+	}
+}
+
+func TestPromoStatusAt(t *testing.T) {
+	reference := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		expiresAt time.Time
+		paused    bool
+		want      string
+	}{
+		{"running", reference.Add(-time.Hour), reference.Add(time.Hour), false, "active"},
+		{"running paused", reference.Add(-time.Hour), reference.Add(time.Hour), true, "paused"},
+		{"starts at reference", reference, reference.Add(time.Hour), false, "active"},
+		{"not started", reference.Add(time.Hour), reference.Add(2 * time.Hour), false, "scheduled"},
+		{"not started paused", reference.Add(time.Hour), reference.Add(2 * time.Hour), true, "scheduled"},
+		{"ended", reference.Add(-2 * time.Hour), reference.Add(-time.Hour), false, "expired"},
+		{"ends at reference", reference.Add(-time.Hour), reference, true, "expired"},
+	}
+	for _, tt := range tests {
+		if got := promoStatusAt(tt.createdAt, tt.expiresAt, reference, tt.paused); got != tt.want {
+			t.Errorf("%s: promoStatusAt = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratePromo(t *testing.T) {
+	cfg, err := ParsePromoConfig("2026-03-01", DefaultPromoDurations, DefaultPromoLookback, DefaultPromoLookahead, 0.2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	durations := make(map[string]promoDuration)
+	for _, d := range cfg.Durations {
+		durations[d.promoType] = d
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	productCreatedAt := cfg.Reference.AddDate(0, 0, -10)
+	statuses := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		p := cfg.generatePromo(rng, productCreatedAt)
+		statuses[p.status]++
+
+		d, ok := durations[p.promoType]
+		if !ok {
+			t.Fatalf("unexpected promo type %q", p.promoType)
+		}
+		if length := p.expiresAt.Sub(p.createdAt); length < d.min || length > d.max {
+			t.Errorf("%s promo lasts %v, want %v-%v", p.promoType, length, d.min, d.max)
+		}
+		if p.createdAt.Before(productCreatedAt) {
+			t.Errorf("promo starts at %v, before its product was created at %v", p.createdAt, productCreatedAt)
+		}
+		if want := promoStatusAt(p.createdAt, p.expiresAt, cfg.Reference, p.status == "paused"); p.status != want {
+			t.Errorf("promo %v-%v has status %q, want %q", p.createdAt, p.expiresAt, p.status, want)
+		}
+		if (p.status == "scheduled") != (p.lastUpdatedAt == nil) {
+			t.Errorf("%s promo has last_updated_at %v", p.status, p.lastUpdatedAt)
+		} else if p.lastUpdatedAt != nil && (p.lastUpdatedAt.Before(p.createdAt) || p.lastUpdatedAt.After(p.expiresAt)) {
+			t.Errorf("%s promo %v-%v last updated at %v", p.status, p.createdAt, p.expiresAt, *p.lastUpdatedAt)
+		}
+	}
+	for _, status := range []string{"scheduled", "active", "paused", "expired"} {
+		if statuses[status] == 0 {
+			t.Errorf("no %s promos generated: %v", status, statuses)
+		}
+	}
+}
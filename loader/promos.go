@@ -0,0 +1,224 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tiny-cds-loader/writer"
+)
+
+// promoColumns are the columns written for promos
+var promoColumns = []string{"product_promo_id", "product_id", "promo_type", "status", "expires_at", "created_at", "last_updated_at"}
+
+// PromoRow is one generated product_promo row
+type PromoRow struct {
+	ID            int64
+	ProductID     int64
+	Type          string
+	Status        string // Derived from the dates at the reference time of the config
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+	LastUpdatedAt *time.Time // Last status transition, nil for scheduled promos
+}
+
+// PromoRows returns the product_promo rows of promos
+func PromoRows(promos []PromoRow) writer.Rows {
+	rows := writer.Rows{Table: "product_promo", Columns: promoColumns, Values: make([][]interface{}, 0, len(promos))}
+	for _, p := range promos {
+		var lastUpdatedAt interface{}
+		if p.LastUpdatedAt != nil {
+			lastUpdatedAt = *p.LastUpdatedAt
+		}
+		rows.Values = append(rows.Values, []interface{}{p.ID, p.ProductID, p.Type, p.Status, p.ExpiresAt, p.CreatedAt, lastUpdatedAt})
+	}
+	return rows
+}
+
+// GeneratePromos returns one promo for each product, numbered from startID; a product can only have one promo
+func (cfg PromoConfig) GeneratePromos(rng *rand.Rand, startID int64, products []ProductRef) []PromoRow {
+	promos := make([]PromoRow, 0, len(products))
+	for i, product := range products {
+		promo := cfg.generatePromo(rng, product.CreatedAt)
+		p := PromoRow{
+			ID:            startID + int64(i),
+			ProductID:     product.ID,
+			Type:          promo.promoType,
+			Status:        promo.status,
+			ExpiresAt:     promo.expiresAt,
+			CreatedAt:     promo.createdAt,
+			LastUpdatedAt: promo.lastUpdatedAt,
+		}
+		promos = append(promos, p)
+	}
+	return promos
+}
+
+// ActivePromo returns a promo of the first configured type running at the reference time for a product created
+// before it, so a fixture can count on one active promo whatever the random dates of the others
+func (cfg PromoConfig) ActivePromo(id int64, product ProductRef) PromoRow {
+	d := cfg.Durations[0]
+	createdAt := cfg.Reference.Add(-d.min / 2)
+	if createdAt.Before(product.CreatedAt) {
+		createdAt = product.CreatedAt
+	}
+	expiresAt := createdAt.Add(d.max)
+	return PromoRow{
+		ID:            id,
+		ProductID:     product.ID,
+		Type:          d.promoType,
+		Status:        promoStatusAt(createdAt, expiresAt, cfg.Reference, false),
+		ExpiresAt:     expiresAt,
+		CreatedAt:     createdAt,
+		LastUpdatedAt: &createdAt,
+	}
+}
+
+// ImportPromos gives promoCount products without a promo one each
+func (l *Loader) ImportPromos(promoCount int, cfg PromoConfig, sample SampleConfig) error {
+	fmt.Print("\n=== Importing Product Promos ===\n\n")
+	fmt.Printf("Importing %d promos using %d workers...\n", promoCount, Workers)
+	fmt.Printf("Promo durations: %s\n", cfg)
+	fmt.Printf("Statuses derived at %s, starts from %d days before to %d days after\n",
+		cfg.Reference.Format(time.RFC3339), int(cfg.Lookback.Hours()/24), int(cfg.Lookahead.Hours()/24))
+
+	sample.PublishedOnly = !cfg.IncludeUnpublished
+	fmt.Printf("Sampling: %s\n", sample)
+
+	products, err := loadProductSampler(l.db, sample)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Loaded %d products from database\n", products.len())
+
+	// product_id is the primary key of product_promo, so products that already have a promo are excluded
+	existing, err := loadPromoProductIDs(l.db)
+	if err != nil {
+		return err
+	}
+
+	// Promo targets are chosen once up front without replacement, so batches never collide
+	targets, err := products.sampleDistinct(l.newRand(-1), promoCount, existing)
+	if err != nil {
+		return fmt.Errorf("not enough products without a promo (%d already have one): %w", len(existing), err)
+	}
+
+	// Get the starting promo ID
+	startPromoID, err := l.dialect.MaxID(l.db, "product_promo", "product_promo_id")
+	if err != nil {
+		return fmt.Errorf("failed to get starting promo ID: %w", err)
+	}
+	startPromoID++ // Start from next available ID
+
+	bar := l.newProgressBar(promoCount, "Promos")
+
+	// Create work channel
+	type promoBatch struct {
+		startPromoID int64
+		targets      []int
+	}
+
+	jobs := make(chan promoBatch, 100)
+	errors := make(chan error, 1000)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	totalInserted := 0
+	var firstError error
+
+	// Error collector goroutine
+	var errorWg sync.WaitGroup
+	errorWg.Add(1)
+	go func() {
+		defer errorWg.Done()
+		for err := range errors {
+			if err != nil && firstError == nil {
+				mu.Lock()
+				if firstError == nil {
+					firstError = err
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	// Start worker goroutines
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			rng := l.newRand(int64(workerID) * 1000)
+
+			for batch := range jobs {
+				refs := make([]ProductRef, len(batch.targets))
+				for i, target := range batch.targets {
+					refs[i] = products.ref(target)
+				}
+
+				// Only a promo created concurrently by another run can conflict, never overwrite it
+				inserted, err := l.sink.Write(PromoRows(cfg.GeneratePromos(rng, batch.startPromoID, refs)))
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: %w", workerID, err)
+					continue
+				}
+
+				mu.Lock()
+				totalInserted += int(inserted[0])
+				bar.Add(len(batch.targets))
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	// Send jobs to workers
+	go func() {
+		batchSize := 1000 // Smaller batches for promos
+
+		for start := 0; start < len(targets); start += batchSize {
+			end := start + batchSize
+			if end > len(targets) {
+				end = len(targets)
+			}
+
+			jobs <- promoBatch{startPromoID: startPromoID + int64(start), targets: targets[start:end]}
+		}
+		close(jobs)
+	}()
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(errors)
+
+	// Wait for error collector to finish
+	errorWg.Wait()
+
+	// Check if there was an error
+	if firstError != nil {
+		return firstError
+	}
+
+	fmt.Printf("\n  ✓ Inserted: %d promos\n\n", totalInserted)
+	return nil
+}
+
+// loadPromoProductIDs returns the products that already have a promo
+func loadPromoProductIDs(db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.Query("SELECT product_id FROM product_promo")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing promos: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan promo product ID: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
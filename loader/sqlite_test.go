@@ -0,0 +1,128 @@
+package loader
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"tiny-cds-loader/writer"
+)
+
+// TestSQLiteTinyProfile loads the tiny profile from categories to downloads into a SQLite file, the way
+// -driver=sqlite -mode=all does
+func TestSQLiteTinyProfile(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "tiny.db")+"?_time_format=sqlite&_pragma=journal_mode(WAL)&_pragma=busy_timeout(10000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	dialect := writer.SQLite{}
+	if _, err := dialect.Prepare(db, "main", true); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := NewDatasetPlan("tiny", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locales, err := ParseLocaleConfig(DefaultLocales)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := ParseStatusConfig("", DefaultProductStatuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pricing, err := ParsePricingConfig("", DefaultCharmRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample, err := ParseSampleConfig(100, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	promo, err := ParsePromoConfig("", DefaultPromoDurations, DefaultPromoLookback, DefaultPromoLookahead, DefaultPromoPaused, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timing, err := ParseDownloadTimeConfig(DefaultDownloadDays, DefaultDiurnalAmplitude, DefaultWeeklyAmplitude, 0, "", DefaultDownloadTimezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := New(db, dialect, Options{Seed: 42, Quiet: true})
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"categories", func() error { return l.ImportCategories(locales) }},
+		{"subcategories", func() error { return l.ImportSubcategories(plan.Subcategories, DefaultCategoryDepth, locales) }},
+		{"tags", func() error {
+			return l.ImportTags(plan.Tags, TagMetadataConfig{
+				CuratedRatio:  DefaultCuratedTagRatio,
+				LandingRatio:  DefaultLandingTagRatio,
+				CategoryRatio: DefaultCategoryTagRatio,
+				PopularityExp: DefaultTagPopularityExp,
+			})
+		}},
+		{"products", func() error {
+			return l.ImportProducts(plan.Products, ProductConfig{
+				Locales:  locales,
+				Statuses: statuses,
+				Pricing:  pricing,
+				Timeline: CatalogAgeConfig{Years: DefaultCatalogYears, Growth: DefaultCatalogGrowth},
+				Authors:  AuthorConfig{Count: plan.Authors, Alpha: DefaultAuthorAlpha, Seed: DefaultAuthorSeed, CrossCategoryRate: DefaultCrossCategoryRate},
+				Payload:  PayloadConfig{ImagesMean: DefaultImagesMean, AssetsMean: DefaultAssetsMean, MetadataScale: DefaultMetadataScale},
+			})
+		}},
+		{"promos", func() error { return l.ImportPromos(plan.Promos, promo, sample) }},
+		{"downloads", func() error {
+			return l.ImportDownloads(plan.Downloads, timing, DownloadPopularityConfig{
+				Zipf:            DefaultPopularityZipf,
+				RecencyBoost:    DefaultRecencyBoost,
+				RecencyHalfLife: DefaultRecencyHalfLife,
+				PromoBoost:      DefaultPromoBoost,
+				Trending:        DefaultTrendingProducts,
+				TrendingHours:   DefaultTrendingHours,
+				TrendingShare:   DefaultTrendingShare,
+			}, sample)
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+	}
+
+	for table, want := range map[string]int{
+		"category":         len(categories) + plan.Subcategories,
+		"tag":              plan.Tags,
+		"product":          plan.Products,
+		"product_promo":    plan.Promos,
+		"product_download": plan.Downloads,
+	} {
+		var got int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s has %d rows, want %d", table, got, want)
+		}
+	}
+
+	// Every generated reference must exist
+	var dangling int
+	err = db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM product_tag pt WHERE NOT EXISTS (SELECT 1 FROM tag t WHERE t.tag_id = pt.tag_id))
+		     + (SELECT COUNT(*) FROM product_download d WHERE NOT EXISTS (SELECT 1 FROM product p WHERE p.product_id = d.product_id))
+		     + (SELECT COUNT(*) FROM product_promo pp WHERE NOT EXISTS (SELECT 1 FROM product p WHERE p.product_id = pp.product_id))
+	`).Scan(&dangling)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dangling > 0 {
+		t.Errorf("%d rows reference missing tags or products", dangling)
+	}
+}
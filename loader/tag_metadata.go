@@ -0,0 +1,119 @@
+package loader
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+const (
+	DefaultCuratedTagRatio  = 0.02
+	DefaultLandingTagRatio  = 0.01
+	DefaultCategoryTagRatio = 0.005
+	DefaultTagPopularityExp = 3.0
+)
+
+// TagMetadataConfig controls the share of tags flagged as curated, landing-page or category tags.
+// Tags are ranked by tag_id (lower IDs are treated as more popular) and the flags are biased toward
+// the popular end while keeping the configured overall ratios.
+type TagMetadataConfig struct {
+	CuratedRatio  float64
+	LandingRatio  float64
+	CategoryRatio float64
+	PopularityExp float64 // Strength of the bias toward popular tags (0 = uniform)
+}
+
+// tagMetadata holds the generated flags and optional landing page content for one tag
+type tagMetadata struct {
+	inLandingPage bool
+	category      bool
+	curated       bool
+	pageContent   string // Empty for tags without a landing page
+}
+
+// tagMetadataCounts tallies the flags produced during an import
+type tagMetadataCounts struct {
+	landing  int
+	category int
+	curated  int
+}
+
+func (c *tagMetadataCounts) add(t TagRow) {
+	if t.InLandingPage {
+		c.landing++
+	}
+	if t.Category {
+		c.category++
+	}
+	if t.Curated {
+		c.curated++
+	}
+}
+
+func (cfg TagMetadataConfig) validate() error {
+	for name, ratio := range map[string]float64{"curated": cfg.CuratedRatio, "landing page": cfg.LandingRatio, "category": cfg.CategoryRatio} {
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("%s tag ratio must be between 0 and 1, got %.4f", name, ratio)
+		}
+	}
+	if cfg.PopularityExp < 0 {
+		return fmt.Errorf("tag popularity exponent must be >= 0, got %.2f", cfg.PopularityExp)
+	}
+	return nil
+}
+
+// flagProbability returns the chance that the tag at the given rank gets a flag with the given overall ratio.
+// The density (k+1)*(1-x)^k integrates to 1 over [0,1), so the overall ratio is preserved. The exponent is
+// lowered for large ratios until ratio*(k+1), the chance of the most popular tag, is at most 1, so no
+// probability has to be clipped and the ratio is met.
+func (cfg TagMetadataConfig) flagProbability(ratio float64, tagID int64, total int) float64 {
+	x := float64(tagID-1) / float64(total)
+	if x < 0 {
+		x = 0
+	}
+	if x >= 1 || ratio <= 0 {
+		return 0
+	}
+	exp := math.Min(cfg.PopularityExp, 1/ratio-1)
+	return ratio * (exp + 1) * math.Pow(1-x, exp)
+}
+
+func generateTagMetadata(rng *rand.Rand, cfg TagMetadataConfig, tagID int64, total int, slug string) tagMetadata {
+	m := tagMetadata{
+		inLandingPage: rng.Float64() < cfg.flagProbability(cfg.LandingRatio, tagID, total),
+		category:      rng.Float64() < cfg.flagProbability(cfg.CategoryRatio, tagID, total),
+		curated:       rng.Float64() < cfg.flagProbability(cfg.CuratedRatio, tagID, total),
+	}
+	if m.inLandingPage {
+		m.pageContent = generateTagPageContent(rng, slug)
+	}
+	return m
+}
+
+var pageContentSentences = []string{
+	"Discover our collection of %s resources, hand-picked for designers and makers.",
+	"Every %s item is ready to download and use in personal and commercial projects.",
+	"Browse thousands of %s files in the formats you already work with.",
+	"Our community of independent creators adds new %s designs every day.",
+	"Whether you need a quick accent or a complete set, these %s assets have you covered.",
+	"Combine %s elements with fonts and templates to build a consistent look.",
+	"Filter %s results by category, file type and price to find the perfect match.",
+	"Popular %s picks are updated weekly based on what other customers love.",
+	"Use %s designs for invitations, packaging, social media posts and more.",
+	"All %s products include instant access and lifetime updates.",
+}
+
+// generateTagPageContent builds a multi-paragraph landing page text for a tag
+func generateTagPageContent(rng *rand.Rand, slug string) string {
+	topic := strings.ReplaceAll(slug, "-", " ")
+	paragraphs := make([]string, 3+rng.Intn(3))
+	for i := range paragraphs {
+		sentences := make([]string, 2+rng.Intn(3))
+		for j := range sentences {
+			sentences[j] = fmt.Sprintf(pageContentSentences[rng.Intn(len(pageContentSentences))], topic)
+		}
+		paragraphs[i] = strings.Join(sentences, " ")
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTagMetadataConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     TagMetadataConfig
+		wantErr bool
+	}{
+		{TagMetadataConfig{CuratedRatio: 0.02, LandingRatio: 0.01, CategoryRatio: 0.005, PopularityExp: 3}, false},
+		{TagMetadataConfig{CuratedRatio: 1, LandingRatio: 0, CategoryRatio: 0}, false},
+		{TagMetadataConfig{CuratedRatio: 1.5}, true},
+		{TagMetadataConfig{LandingRatio: -0.1}, true},
+		{TagMetadataConfig{PopularityExp: -1}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: got error %v, want error %t", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFlagProbability(t *testing.T) {
+	const total = 100000
+	tests := []struct {
+		exp   float64
+		ratio float64
+	}{
+		{0, 0.01},
+		{1, 0.01},
+		{3, 0.02},
+		{3, 0.5}, // Exponent lowered to 1
+		{3, 0.9},
+		{3, 1},
+		{3, 0},
+	}
+	for _, tt := range tests {
+		cfg := TagMetadataConfig{PopularityExp: tt.exp}
+		sum := 0.0
+		for id := int64(1); id <= total; id++ {
+			p := cfg.flagProbability(tt.ratio, id, total)
+			if p < 0 || p > 1 {
+				t.Fatalf("exponent %g, ratio %g: tag %d has probability %.4f", tt.exp, tt.ratio, id, p)
+			}
+			sum += p
+		}
+		if mean := sum / total; math.Abs(mean-tt.ratio) > 0.01*tt.ratio+1e-9 {
+			t.Errorf("exponent %g, ratio %g: mean probability %.4f", tt.exp, tt.ratio, mean)
+		}
+		if tt.exp > 0 && tt.ratio > 0 && tt.ratio < 1 && cfg.flagProbability(tt.ratio, 1, total) <= cfg.flagProbability(tt.ratio, total, total) {
+			t.Errorf("exponent %g, ratio %g: the first tag is not favored over the last", tt.exp, tt.ratio)
+		}
+		if p := cfg.flagProbability(tt.ratio, total+1, total); p != 0 {
+			t.Errorf("exponent %g, ratio %g: tag beyond the catalog has probability %.4f", tt.exp, tt.ratio, p)
+		}
+	}
+}
+
+func TestTagGeneratorMetadata(t *testing.T) {
+	// Large ratios are met too, the curated one needs a lower exponent
+	cfg := TagMetadataConfig{CuratedRatio: 0.5, LandingRatio: 0.01, CategoryRatio: 0.005, PopularityExp: 3}
+	rng := rand.New(rand.NewSource(1))
+	const total = 200000
+
+	generator, err := NewTagGenerator(cfg, total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var counts tagMetadataCounts
+	for _, tag := range generator.Generate(rng, 1, total) {
+		if tag.InLandingPage != (tag.PageContent != "") {
+			t.Fatalf("tag %d: landing page %t with page content %q", tag.ID, tag.InLandingPage, tag.PageContent)
+		}
+		counts.add(tag)
+	}
+
+	for _, c := range []struct {
+		name  string
+		got   int
+		ratio float64
+	}{
+		{"curated", counts.curated, cfg.CuratedRatio},
+		{"landing page", counts.landing, cfg.LandingRatio},
+		{"category", counts.category, cfg.CategoryRatio},
+	} {
+		if got := float64(c.got) / total; math.Abs(got-c.ratio) > c.ratio*0.15 {
+			t.Errorf("%s ratio %.4f, want %.4f", c.name, got, c.ratio)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+const (
+	tagRelationBatchSize      = 1000 // Source tags per batch in random mode
+	tagCoOccurrenceBatchSize  = 200  // Source tags per batch in co-occurrence mode (each one is a self-join on product_tag)
+	tagCoOccurrenceProducts   = 1000 // Products per source tag the co-occurrence is counted on, so hot tags stay cheap
+	DefaultTagRelationDegree  = 8
+	DefaultTagRelationSkew    = 2.0
+	DefaultTagRelationMaxEdge = 200
+)
+
+// TagRelationConfig controls the shape of the related-tags graph
+type TagRelationConfig struct {
+	AvgDegree    float64 // Average number of related tags per source tag
+	Skew         float64 // Pareto shape of the degree distribution, must be > 1 (lower = more skewed)
+	MaxDegree    int     // Upper bound for the degree of a single tag
+	SourceCount  int     // Number of source tags to relate (0 = every tag)
+	CoOccurrence bool    // Derive related tags from product_tag co-occurrence instead of random picks
+}
+
+// tagRelationStats aggregates the degrees produced by the workers and the rows they inserted
+type tagRelationStats struct {
+	relations   int
+	inserted    int64
+	sourceTags  int
+	maxDegree   int
+	degreeCount map[string]int
+}
+
+var tagRelationBuckets = []struct {
+	label string
+	max   int
+}{
+	{"0", 0},
+	{"1-5", 5},
+	{"6-10", 10},
+	{"11-25", 25},
+	{"26-50", 50},
+	{"51+", math.MaxInt},
+}
+
+func (s *tagRelationStats) add(degree int) {
+	s.sourceTags++
+	s.relations += degree
+	if degree > s.maxDegree {
+		s.maxDegree = degree
+	}
+	for _, b := range tagRelationBuckets {
+		if degree <= b.max {
+			s.degreeCount[b.label]++
+			return
+		}
+	}
+}
+
+func (l *Loader) ImportTagRelations(cfg TagRelationConfig) error {
+	fmt.Print("\n=== Importing Tag Relations ===\n\n")
+
+	if cfg.Skew <= 1 {
+		return fmt.Errorf("relation skew must be > 1, got %.2f", cfg.Skew)
+	}
+	if cfg.AvgDegree <= 0 {
+		return fmt.Errorf("average relation degree must be > 0, got %.2f", cfg.AvgDegree)
+	}
+
+	fmt.Println("Loading tag IDs from database...")
+	tagIDs, err := loadTagIDs(l.db)
+	if err != nil {
+		return err
+	}
+	if len(tagIDs) < 2 {
+		return fmt.Errorf("at least 2 tags are required - please import tags first")
+	}
+	fmt.Printf("Loaded %d tags\n", len(tagIDs))
+
+	rng := l.newRand(-1)
+
+	sources := tagIDs
+	if cfg.SourceCount > 0 && cfg.SourceCount < len(tagIDs) {
+		sources = make([]int64, len(tagIDs))
+		copy(sources, tagIDs)
+		rng.Shuffle(len(sources), func(i, j int) {
+			sources[i], sources[j] = sources[j], sources[i]
+		})
+		sources = sources[:cfg.SourceCount]
+	}
+
+	maxDegree := cfg.MaxDegree
+	if maxDegree <= 0 || maxDegree > len(tagIDs)-1 {
+		maxDegree = len(tagIDs) - 1
+	}
+
+	source := "random"
+	batchSize := tagRelationBatchSize
+	if cfg.CoOccurrence {
+		source = "product_tag co-occurrence"
+		batchSize = tagCoOccurrenceBatchSize
+	}
+	fmt.Printf("Relating %d tags (avg degree %.1f, skew %.2f, max %d, source: %s) using %d workers...\n",
+		len(sources), cfg.AvgDegree, cfg.Skew, maxDegree, source, Workers)
+
+	bar := l.newProgressBar(len(sources), "Tag Relations")
+
+	jobs := make(chan []int64, 100)
+	errors := make(chan error, 1000)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stats := tagRelationStats{degreeCount: make(map[string]int)}
+	var firstError error
+
+	// Error collector goroutine
+	var errorWg sync.WaitGroup
+	errorWg.Add(1)
+	go func() {
+		defer errorWg.Done()
+		for err := range errors {
+			if err != nil && firstError == nil {
+				mu.Lock()
+				if firstError == nil {
+					firstError = err
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	// Start worker goroutines
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			rng := l.newRand(int64(workerID) * 1000)
+
+			for batch := range jobs {
+				degrees := make([]int, len(batch))
+				for i := range batch {
+					degrees[i] = sampleRelationDegree(rng, cfg.AvgDegree, cfg.Skew, maxDegree)
+				}
+
+				var related map[int64][]int64
+				var err error
+				if cfg.CoOccurrence {
+					related, err = coOccurringTags(l.db, batch, degrees)
+				} else {
+					related = randomRelatedTags(rng, batch, degrees, tagIDs)
+				}
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: %w", workerID, err)
+					continue
+				}
+
+				inserted, err := insertTagRelationBatch(l.db, related)
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: %w", workerID, err)
+					continue
+				}
+
+				mu.Lock()
+				stats.inserted += inserted
+				for _, tagID := range batch {
+					stats.add(len(related[tagID]))
+				}
+				bar.Add(len(batch))
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	// Send jobs to workers
+	go func() {
+		for start := 0; start < len(sources); start += batchSize {
+			end := start + batchSize
+			if end > len(sources) {
+				end = len(sources)
+			}
+			jobs <- sources[start:end]
+		}
+		close(jobs)
+	}()
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(errors)
+
+	// Wait for error collector to finish
+	errorWg.Wait()
+
+	// Check if there was an error
+	if firstError != nil {
+		return firstError
+	}
+
+	avg := 0.0
+	if stats.sourceTags > 0 {
+		avg = float64(stats.relations) / float64(stats.sourceTags)
+	}
+	// Relations that already existed are skipped by ON CONFLICT, the degrees are the generated ones
+	fmt.Printf("\n  ✓ Inserted: %d tag relations (%d generated, avg degree %.2f, max degree %d)\n", stats.inserted, stats.relations, avg, stats.maxDegree)
+	fmt.Println("  Degree distribution:")
+	for _, b := range tagRelationBuckets {
+		fmt.Printf("    %-6s %d tags\n", b.label, stats.degreeCount[b.label])
+	}
+	fmt.Println()
+	return nil
+}
+
+// loadTagIDs returns every tag_id present in the tag table, sorted ascending
+func loadTagIDs(db *sql.DB) ([]int64, error) {
+	var count int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM tag").Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+
+	rows, err := db.Query("SELECT tag_id FROM tag ORDER BY tag_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tag IDs: %w", err)
+	}
+	defer rows.Close()
+
+	tagIDs := make([]int64, 0, count)
+	for rows.Next() {
+		var tagID int64
+		if err := rows.Scan(&tagID); err != nil {
+			return nil, fmt.Errorf("failed to scan tag ID: %w", err)
+		}
+		tagIDs = append(tagIDs, tagID)
+	}
+	return tagIDs, rows.Err()
+}
+
+// sampleRelationDegree draws a degree from a Pareto distribution scaled so that its mean is avgDegree
+func sampleRelationDegree(rng *rand.Rand, avgDegree, skew float64, maxDegree int) int {
+	// A Pareto(xm, alpha) variable has mean xm*alpha/(alpha-1), so this xm gives a mean of 1
+	xm := (skew - 1) / skew
+	weight := xm / math.Pow(1-rng.Float64(), 1/skew)
+
+	degree := int(math.Round(avgDegree * weight))
+	if degree > maxDegree {
+		degree = maxDegree
+	}
+	return degree
+}
+
+// randomRelatedTags picks distinct related tags for every source tag, never relating a tag to itself
+func randomRelatedTags(rng *rand.Rand, sources []int64, degrees []int, tagIDs []int64) map[int64][]int64 {
+	related := make(map[int64][]int64, len(sources))
+	for i, tagID := range sources {
+		seen := make(map[int64]bool, degrees[i])
+		picks := make([]int64, 0, degrees[i])
+		for len(picks) < degrees[i] {
+			candidate := tagIDs[rng.Intn(len(tagIDs))]
+			if candidate == tagID || seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			picks = append(picks, candidate)
+		}
+		related[tagID] = picks
+	}
+	return related
+}
+
+// coOccurringTags relates every source tag to the tags it most often shares products with, counted on at
+// most tagCoOccurrenceProducts of its products
+func coOccurringTags(db *sql.DB, sources []int64, degrees []int) (map[int64][]int64, error) {
+	wanted := make(map[int64]int, len(sources))
+	for i, tagID := range sources {
+		wanted[tagID] = degrees[i]
+	}
+
+	// The join on tag keeps the FK on related_tag_id satisfied even if product_tag references missing tags
+	rows, err := db.Query(`
+		SELECT pt1.tag_id, pt2.tag_id, COUNT(*) AS shared
+		FROM unnest($1::bigint[]) AS s(tag_id)
+		CROSS JOIN LATERAL (
+			SELECT s.tag_id, product_id FROM product_tag WHERE tag_id = s.tag_id LIMIT $2
+		) pt1
+		INNER JOIN product_tag pt2 ON pt2.product_id = pt1.product_id AND pt2.tag_id <> pt1.tag_id
+		INNER JOIN tag t ON t.tag_id = pt2.tag_id
+		GROUP BY pt1.tag_id, pt2.tag_id
+		ORDER BY pt1.tag_id, shared DESC, pt2.tag_id
+	`, pq.Array(sources), tagCoOccurrenceProducts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute tag co-occurrence: %w", err)
+	}
+	defer rows.Close()
+
+	related := make(map[int64][]int64, len(sources))
+	for rows.Next() {
+		var tagID, relatedID, shared int64
+		if err := rows.Scan(&tagID, &relatedID, &shared); err != nil {
+			return nil, fmt.Errorf("failed to scan co-occurrence: %w", err)
+		}
+		if len(related[tagID]) < wanted[tagID] {
+			related[tagID] = append(related[tagID], relatedID)
+		}
+	}
+	return related, rows.Err()
+}
+
+// insertTagRelationBatch inserts the relations of a batch and returns how many did not exist yet
+func insertTagRelationBatch(db *sql.DB, related map[int64][]int64) (int64, error) {
+	var tagIDs, relatedIDs []int64
+	for tagID, picks := range related {
+		for _, relatedID := range picks {
+			tagIDs = append(tagIDs, tagID)
+			relatedIDs = append(relatedIDs, relatedID)
+		}
+	}
+	if len(tagIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO tag_relation (tag_id, related_tag_id)
+		SELECT * FROM unnest($1::bigint[], $2::bigint[])
+		ON CONFLICT DO NOTHING
+	`, pq.Array(tagIDs), pq.Array(relatedIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert tag relations: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,75 @@
+package loader
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSampleRelationDegree(t *testing.T) {
+	tests := []struct {
+		avgDegree float64
+		skew      float64
+		maxDegree int
+	}{
+		{8, 2, 200},
+		{8, 1.5, 200},
+		{20, 3, 50},
+		{2, 2, 5},
+	}
+	for _, tt := range tests {
+		rng := rand.New(rand.NewSource(1))
+		sum := 0
+		const n = 20000
+		for i := 0; i < n; i++ {
+			degree := sampleRelationDegree(rng, tt.avgDegree, tt.skew, tt.maxDegree)
+			if degree < 0 || degree > tt.maxDegree {
+				t.Fatalf("avg %g, skew %g: degree %d outside [0, %d]", tt.avgDegree, tt.skew, degree, tt.maxDegree)
+			}
+			sum += degree
+		}
+		// The cap only lowers the mean
+		if mean := float64(sum) / n; mean > tt.avgDegree*1.1 || mean < tt.avgDegree*0.5 {
+			t.Errorf("avg %g, skew %g, max %d: mean degree %.2f", tt.avgDegree, tt.skew, tt.maxDegree, mean)
+		}
+	}
+}
+
+func TestRandomRelatedTags(t *testing.T) {
+	tagIDs := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	sources := []int64{1, 5, 10}
+	degrees := []int{9, 3, 0}
+
+	related := randomRelatedTags(rand.New(rand.NewSource(1)), sources, degrees, tagIDs)
+	for i, source := range sources {
+		picks := related[source]
+		if len(picks) != degrees[i] {
+			t.Errorf("tag %d has %d related tags, want %d", source, len(picks), degrees[i])
+		}
+		seen := make(map[int64]bool)
+		for _, id := range picks {
+			if id == source {
+				t.Errorf("tag %d is related to itself", source)
+			}
+			if seen[id] {
+				t.Errorf("tag %d is related to %d twice", source, id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestTagRelationStats(t *testing.T) {
+	stats := &tagRelationStats{degreeCount: make(map[string]int)}
+	for _, degree := range []int{0, 1, 5, 6, 25, 26, 51, 300} {
+		stats.add(degree)
+	}
+
+	if stats.sourceTags != 8 || stats.relations != 414 || stats.maxDegree != 300 {
+		t.Errorf("got %d source tags, %d relations, max degree %d, want 8, 414 and 300", stats.sourceTags, stats.relations, stats.maxDegree)
+	}
+	for label, want := range map[string]int{"0": 1, "1-5": 2, "6-10": 1, "11-25": 1, "26-50": 1, "51+": 2} {
+		if got := stats.degreeCount[label]; got != want {
+			t.Errorf("bucket %s has %d tags, want %d", label, got, want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package loader
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"tiny-cds-loader/writer"
+)
+
+// Word lists for generating random tag slugs
+var adjectives = []string{
+	"abstract", "ancient", "artistic", "beautiful", "bold", "bright", "classic", "clean",
+	"colorful", "creative", "cute", "dark", "decorative", "delicate", "elegant", "fancy",
+	"festive", "floral", "fresh", "fun", "geometric", "golden", "gorgeous", "graceful",
+	"handmade", "happy", "luxury", "magical", "minimal", "modern", "natural", "organic",
+	"ornate", "playful", "premium", "pretty", "retro", "rustic", "seasonal", "simple",
+	"stylish", "trendy", "unique", "urban", "vibrant", "vintage", "wild", "wonderful",
+}
+
+var nouns = []string{
+	"art", "background", "badge", "banner", "border", "bouquet", "card", "celebration",
+	"collection", "decoration", "design", "drawing", "element", "emblem", "flower", "frame",
+	"graphic", "icon", "illustration", "image", "label", "layout", "logo", "ornament",
+	"pattern", "poster", "print", "set", "shape", "sign", "silhouette", "sketch",
+	"sticker", "style", "symbol", "template", "texture", "theme", "vector", "wallpaper",
+	"watercolor", "wreath", "bundle", "pack", "kit", "clipart", "mockup", "scene",
+}
+
+// tagColumns are the columns written for tags
+var tagColumns = []string{"tag_id", "slug", "in_landing_page", "category", "curated", "page_content"}
+
+// TagRow is one generated tag
+type TagRow struct {
+	ID            int64
+	Slug          string
+	InLandingPage bool
+	Category      bool
+	Curated       bool
+	PageContent   string // Landing page content, empty (NULL) for tags without a landing page
+}
+
+// TagRows returns the tag table rows of tags
+func TagRows(tags []TagRow) writer.Rows {
+	rows := writer.Rows{Table: "tag", Columns: tagColumns, Values: make([][]interface{}, 0, len(tags))}
+	for _, t := range tags {
+		var pageContent interface{}
+		if t.PageContent != "" {
+			pageContent = t.PageContent
+		}
+		rows.Values = append(rows.Values, []interface{}{t.ID, t.Slug, t.InLandingPage, t.Category, t.Curated, pageContent})
+	}
+	return rows
+}
+
+// TagGenerator generates the tags 1 to total; lower IDs are treated as more popular
+type TagGenerator struct {
+	cfg   TagMetadataConfig
+	total int
+}
+
+// NewTagGenerator returns a generator for a catalog of total tags
+func NewTagGenerator(cfg TagMetadataConfig, total int) (*TagGenerator, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &TagGenerator{cfg: cfg, total: total}, nil
+}
+
+// Generate returns count tags numbered from startID
+func (g *TagGenerator) Generate(rng *rand.Rand, startID int64, count int) []TagRow {
+	tags := make([]TagRow, 0, count)
+	for i := 0; i < count; i++ {
+		tagID := startID + int64(i)
+		slug := generateRandomTagSlug(rng)
+		meta := generateTagMetadata(rng, g.cfg, tagID, g.total, slug)
+
+		tags = append(tags, TagRow{
+			ID:            tagID,
+			Slug:          slug,
+			InLandingPage: meta.inLandingPage,
+			Category:      meta.category,
+			Curated:       meta.curated,
+			PageContent:   meta.pageContent,
+		})
+	}
+	return tags
+}
+
+// ImportTags inserts the tags 1 to tagCount, skipping the existing ones
+func (l *Loader) ImportTags(tagCount int, cfg TagMetadataConfig) error {
+	fmt.Print("\n=== Importing Tags ===\n\n")
+
+	generator, err := NewTagGenerator(cfg, tagCount)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Importing %d tags in batches of %d using %d workers...\n", tagCount, batchSize, Workers)
+	fmt.Printf("Target ratios: %.2f%% landing page, %.2f%% category, %.2f%% curated (popularity bias %.1f)\n",
+		cfg.LandingRatio*100, cfg.CategoryRatio*100, cfg.CuratedRatio*100, cfg.PopularityExp)
+
+	bar := l.newProgressBar(tagCount, "Tags")
+
+	// Create work channel and error channel
+	type batchJob struct {
+		start int
+		end   int
+	}
+
+	jobs := make(chan batchJob, 100)
+	errors := make(chan error, Workers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	totalInserted := 0
+	var metadataCounts tagMetadataCounts
+
+	// Start worker goroutines
+	for w := 0; w < Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+
+			// Each worker has its own random generator
+			rng := l.newRand(int64(workerID) * 1000)
+
+			for job := range jobs {
+				batchCount := job.end - job.start + 1
+				tags := generator.Generate(rng, int64(job.start), batchCount)
+
+				inserted, err := l.sink.Write(TagRows(tags))
+				if err != nil {
+					errors <- fmt.Errorf("worker %d: failed to insert batch starting at %d: %w", workerID, job.start, err)
+					continue
+				}
+
+				// Update progress
+				mu.Lock()
+				totalInserted += int(inserted[0])
+				for _, tag := range tags {
+					metadataCounts.add(tag)
+				}
+				bar.Add(batchCount)
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	// Send jobs to workers
+	go func() {
+		for batchStart := 1; batchStart <= tagCount; batchStart += batchSize {
+			batchEnd := batchStart + batchSize - 1
+			if batchEnd > tagCount {
+				batchEnd = tagCount
+			}
+			jobs <- batchJob{start: batchStart, end: batchEnd}
+		}
+		close(jobs)
+	}()
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(errors)
+
+	// Check for errors
+	for err := range errors {
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n  ✓ Inserted: %d tags\n", totalInserted)
+	fmt.Printf("  Landing page: %d, Category: %d, Curated: %d\n\n", metadataCounts.landing, metadataCounts.category, metadataCounts.curated)
+	return nil
+}
+
+func generateRandomTagSlug(rng *rand.Rand) string {
+	// Generate random combinations of adjective + noun, or just noun
+	if rng.Intn(2) == 0 {
+		// adjective + noun
+		adj := adjectives[rng.Intn(len(adjectives))]
+		noun := nouns[rng.Intn(len(nouns))]
+		return fmt.Sprintf("%s-%s", adj, noun)
+	}
+	// just noun
+	return nouns[rng.Intn(len(nouns))]
+}
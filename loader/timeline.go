@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	DefaultCatalogYears  = 8.0
+	DefaultCatalogGrowth = 0.35 // Each year sees 35% more new products than the previous one
+	hoursPerYear         = 365.25 * 24
+)
+
+// CatalogAgeConfig controls how product created_at values are spread over time
+type CatalogAgeConfig struct {
+	Years  float64 // Age of the oldest product
+	Growth float64 // Yearly growth of the number of new products (0 = linear catalog growth)
+}
+
+func (cfg CatalogAgeConfig) validate() error {
+	if cfg.Years < 0 {
+		return fmt.Errorf("catalog age must be >= 0 years, got %.2f", cfg.Years)
+	}
+	if cfg.Growth <= -1 {
+		return fmt.Errorf("catalog growth must be > -1, got %.2f", cfg.Growth)
+	}
+	return nil
+}
+
+// productTimeline maps a product ID to its created_at. IDs are spread over [start, end] with an
+// exponentially growing rate of new products, and later IDs always get later timestamps.
+type productTimeline struct {
+	startID int64
+	count   int64
+	start   time.Time
+	span    time.Duration
+	rate    float64 // Continuous growth rate per span (k*T)
+}
+
+// newProductTimeline creates the timeline of the products [startID, startID+count).
+// It starts at the newest existing created_at when that is more recent than the configured age,
+// so appending runs stay monotonic with product_id.
+func newProductTimeline(cfg CatalogAgeConfig, startID int64, count int, newestExisting time.Time, now time.Time) *productTimeline {
+	start := now.Add(-time.Duration(cfg.Years * hoursPerYear * float64(time.Hour)))
+	if newestExisting.After(start) {
+		start = newestExisting
+	}
+	if start.After(now) {
+		start = now
+	}
+
+	span := now.Sub(start)
+	return &productTimeline{
+		startID: startID,
+		count:   int64(count),
+		start:   start,
+		span:    span,
+		rate:    math.Log1p(cfg.Growth) * span.Hours() / hoursPerYear,
+	}
+}
+
+// createdAt returns the creation time of a product by inverting the CDF of an exponential density
+func (t *productTimeline) createdAt(productID int64) time.Time {
+	u := (float64(productID-t.startID) + 0.5) / float64(t.count)
+	fraction := u
+	if math.Abs(t.rate) > 1e-9 {
+		fraction = math.Log1p(u*math.Expm1(t.rate)) / t.rate
+	}
+	return t.start.Add(time.Duration(fraction * float64(t.span)))
+}
+
+// newestProductCreatedAt returns the latest created_at in the product table, or the zero time
+func newestProductCreatedAt(db *sql.DB) (time.Time, error) {
+	// Selecting the column rather than MAX() keeps its type, which SQLite needs to return a time
+	var newest time.Time
+	err := db.QueryRow("SELECT created_at FROM product ORDER BY created_at DESC LIMIT 1").Scan(&newest)
+	if err != nil && err != sql.ErrNoRows {
+		return time.Time{}, fmt.Errorf("failed to get newest product created_at: %w", err)
+	}
+	return newest, nil
+}
+
+// verifyProductTimeline checks that created_at grows with product_id and prints products per year
+func verifyProductTimeline(db *sql.DB, report *verifyReport) error {
+	var backwards int64
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM (
+			SELECT created_at < LAG(created_at) OVER (ORDER BY product_id) AS backwards
+			FROM product
+		) s
+		WHERE backwards
+	`).Scan(&backwards)
+	if err != nil {
+		return fmt.Errorf("failed to check created_at ordering: %w", err)
+	}
+	report.check("created_at is monotonic with product_id", backwards == 0, fmt.Sprintf("%d products created before their predecessor", backwards))
+
+	rows, err := db.Query(`
+		SELECT EXTRACT(YEAR FROM created_at)::int AS year, COUNT(*)
+		FROM product
+		GROUP BY year
+		ORDER BY year
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to count products per year: %w", err)
+	}
+	defer rows.Close()
+
+	fmt.Println("  Products created per year:")
+	for rows.Next() {
+		var year, count int64
+		if err := rows.Scan(&year, &count); err != nil {
+			return fmt.Errorf("failed to scan year count: %w", err)
+		}
+		fmt.Printf("    %d: %d\n", year, count)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,69 @@
+package loader
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCatalogAgeConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     CatalogAgeConfig
+		wantErr bool
+	}{
+		{CatalogAgeConfig{Years: DefaultCatalogYears, Growth: DefaultCatalogGrowth}, false},
+		{CatalogAgeConfig{Years: 0, Growth: 0}, false},
+		{CatalogAgeConfig{Years: 2, Growth: -0.5}, false},
+		{CatalogAgeConfig{Years: -1}, true},
+		{CatalogAgeConfig{Years: 2, Growth: -1}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: got error %v, want error %t", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestProductTimeline(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	const count = 10000
+
+	tests := []struct {
+		name      string
+		cfg       CatalogAgeConfig
+		newest    time.Time
+		wantStart time.Time
+		growing   bool // More products in the last year than in the first
+	}{
+		{"growing catalog", CatalogAgeConfig{Years: 8, Growth: 0.35}, time.Time{}, now.Add(-time.Duration(8 * hoursPerYear * float64(time.Hour))), true},
+		{"linear catalog", CatalogAgeConfig{Years: 8, Growth: 0}, time.Time{}, now.Add(-time.Duration(8 * hoursPerYear * float64(time.Hour))), false},
+		{"appended after newer products", CatalogAgeConfig{Years: 8, Growth: 0.35}, now.Add(-48 * time.Hour), now.Add(-48 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		timeline := newProductTimeline(tt.cfg, 1001, count, tt.newest, now)
+		if !timeline.start.Equal(tt.wantStart) {
+			t.Errorf("%s: starts at %v, want %v", tt.name, timeline.start, tt.wantStart)
+		}
+
+		first, last := 0, 0
+		prev := timeline.start
+		for id := int64(1001); id < 1001+count; id++ {
+			createdAt := timeline.createdAt(id)
+			if createdAt.Before(prev) || createdAt.After(now) {
+				t.Fatalf("%s: product %d created at %v, after %v and before %v expected", tt.name, id, createdAt, prev, now)
+			}
+			prev = createdAt
+			if createdAt.Sub(timeline.start).Hours() < hoursPerYear {
+				first++
+			}
+			if now.Sub(createdAt).Hours() < hoursPerYear {
+				last++
+			}
+		}
+		if tt.growing && last <= 2*first {
+			t.Errorf("%s: %d products in the first year and %d in the last, want growth", tt.name, first, last)
+		}
+		if tt.cfg.Growth == 0 && tt.newest.IsZero() && (first < count/8-20 || first > count/8+20) {
+			t.Errorf("%s: %d products in the first year, want about %d", tt.name, first, count/8)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// verifyReport collects the outcome of the consistency checks
+type verifyReport struct {
+	failures int
+}
+
+func (r *verifyReport) check(name string, ok bool, detail string) {
+	if ok {
+		fmt.Printf("  ✓ %s\n", name)
+		return
+	}
+	r.failures++
+	fmt.Printf("  ✗ %s: %s\n", name, detail)
+}
+
+// verifySection is one group of checks, printed under its own heading
+type verifySection struct {
+	title string
+	run   func(db *sql.DB, report *verifyReport) error
+}
+
+var verifySections = []verifySection{
+	{"Category hierarchy", verifyCategoryHierarchy},
+	{"Product status", verifyProductStatus},
+	{"Product timeline", verifyProductTimeline},
+	{"Authors", verifyAuthors},
+	{"Prices", verifyPrices},
+	{"Promos", verifyPromos},
+	{"Downloads", verifyDownloads},
+}
+
+func (l *Loader) Verify() error {
+	fmt.Print("\n=== Verifying Dataset ===\n")
+
+	report := &verifyReport{}
+	for _, section := range verifySections {
+		fmt.Printf("\n%s\n", section.title)
+		if err := section.run(l.db, report); err != nil {
+			return fmt.Errorf("%s: %w", section.title, err)
+		}
+	}
+
+	if report.failures > 0 {
+		return fmt.Errorf("%d check(s) failed", report.failures)
+	}
+	return nil
+}
+
+// verifyCategoryHierarchy checks that hierarchy_path and url_path agree with parent_category_id
+func verifyCategoryHierarchy(db *sql.DB, report *verifyReport) error {
+	var missing int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM category WHERE hierarchy_path IS NULL").Scan(&missing); err != nil {
+		return fmt.Errorf("failed to count categories without hierarchy_path: %w", err)
+	}
+	report.check("every category has a hierarchy_path", missing == 0, fmt.Sprintf("%d categories without hierarchy_path", missing))
+
+	// A category's path is its parent's path followed by its own ID (or just its ID at the top level)
+	var inconsistent int64
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM category c
+		LEFT JOIN category p ON p.category_id = c.parent_category_id
+		WHERE c.hierarchy_path IS NOT NULL
+		  AND c.hierarchy_path <> CASE
+		      WHEN c.parent_category_id IS NULL THEN text2ltree(c.category_id::text)
+		      ELSE p.hierarchy_path || text2ltree(c.category_id::text)
+		  END
+	`).Scan(&inconsistent)
+	if err != nil {
+		return fmt.Errorf("failed to check hierarchy_path consistency: %w", err)
+	}
+	report.check("hierarchy_path matches parent_category_id", inconsistent == 0, fmt.Sprintf("%d categories with a path that disagrees with their parent", inconsistent))
+
+	var badURLs int64
+	err = db.QueryRow(`
+		SELECT COUNT(*)
+		FROM category c
+		INNER JOIN category p ON p.category_id = c.parent_category_id
+		WHERE c.url_path NOT LIKE p.url_path || '/%'
+	`).Scan(&badURLs)
+	if err != nil {
+		return fmt.Errorf("failed to check url_path nesting: %w", err)
+	}
+	report.check("url_path is nested under the parent url_path", badURLs == 0, fmt.Sprintf("%d categories with a url_path outside their parent", badURLs))
+
+	rows, err := db.Query(`
+		SELECT nlevel(hierarchy_path) - 1 AS level, COUNT(*)
+		FROM category
+		WHERE hierarchy_path IS NOT NULL
+		GROUP BY level
+		ORDER BY level
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to count categories per level: %w", err)
+	}
+	defer rows.Close()
+
+	fmt.Println("  Categories per level:")
+	for rows.Next() {
+		var level, count int64
+		if err := rows.Scan(&level, &count); err != nil {
+			return fmt.Errorf("failed to scan level count: %w", err)
+		}
+		fmt.Printf("    Level %d: %d\n", level, count)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,313 @@
+// Package loadertest seeds a small, realistic catalog into a throwaway schema for integration tests.
+// The rows come from the generators of the loader package, run in a single goroutine from one seed, so
+// the same seed always gives the same IDs and relations. Timestamps and promo statuses follow
+// Options.Now, which defaults to the current time: set it for fixtures that are identical in every run.
+package loadertest
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"tiny-cds-loader/loader"
+	"tiny-cds-loader/writer"
+)
+
+const (
+	DefaultProfile = "tiny" // 9 categories, 20 subcategories, 500 products
+	DefaultSeed    = 1
+)
+
+// seededTables are the tables Seed writes to, in the order they are filled
+var seededTables = []string{"category", "tag", "product", "product_product_category", "product_tag", "product_promo", "product_download"}
+
+// schemaCounter tells apart the schemas created by one process within the same nanosecond
+var schemaCounter int64
+
+// Options configure Seed
+type Options struct {
+	Profile string         // Dataset profile of the loader, DefaultProfile when empty
+	Scale   float64        // Scale of the profile, 1 when 0
+	Seed    int64          // Base of every generator, DefaultSeed when 0
+	Now     time.Time      // Reference time of every timestamp and promo status, time.Now() when zero; fix it for reproducible fixtures
+	Schema  string         // Schema to create, a unique loadertest_ name when empty
+	Dialect writer.Dialect // writer.Postgres{} when nil; SQLite has no schemas and seeds the main database
+}
+
+// Catalog is a seeded schema with the IDs tests usually need
+type Catalog struct {
+	Schema                 string
+	Plan                   loader.DatasetPlan
+	CategoryIDs            []int64 // Top-level categories
+	SubcategoryIDs         []int64
+	ProductIDs             []int64
+	HotTagID               int64 // Attached to Plan.HotTag products on top of those the generator tagged with it
+	HotTagProducts         int   // Products carrying HotTagID
+	PromoProductID         int64 // Published product with an active promo
+	TopDownloadedProductID int64 // Product with the most downloads
+	TopDownloads           int   // Downloads of TopDownloadedProductID
+
+	db      *sql.DB
+	dialect writer.Dialect
+}
+
+// New seeds a catalog of profile into a new schema of db and drops the schema when the test ends
+func New(t testing.TB, db *sql.DB, profile string) *Catalog {
+	t.Helper()
+	c, err := Seed(db, Options{Profile: profile})
+	if err != nil {
+		t.Fatalf("failed to seed catalog: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := c.Drop(); err != nil {
+			t.Errorf("failed to drop seeded catalog: %v", err)
+		}
+	})
+	return c
+}
+
+// Seed creates a schema and fills it with the categories, subcategories, tags, products, promos and
+// downloads of a profile, written in a single transaction
+func Seed(db *sql.DB, opts Options) (*Catalog, error) {
+	if opts.Profile == "" {
+		opts.Profile = DefaultProfile
+	}
+	if opts.Scale == 0 {
+		opts.Scale = 1
+	}
+	if opts.Seed == 0 {
+		opts.Seed = DefaultSeed
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Dialect == nil {
+		opts.Dialect = writer.Postgres{}
+	}
+
+	plan, err := loader.NewDatasetPlan(opts.Profile, opts.Scale)
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalog{Schema: opts.Schema, Plan: plan, HotTagID: plan.HotTagID, db: db, dialect: opts.Dialect}
+
+	if opts.Dialect.Driver() == "sqlite" {
+		if c.Schema != "" && c.Schema != "main" {
+			return nil, fmt.Errorf("the sqlite driver has no schemas, got %q", c.Schema)
+		}
+		c.Schema = "main"
+		if _, err := opts.Dialect.Prepare(db, c.Schema, true); err != nil {
+			return nil, err
+		}
+		existing, err := opts.Dialect.MaxID(db, c.Table("product"), "product_id")
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up existing products: %w", err)
+		}
+		if existing > 0 {
+			return nil, fmt.Errorf("the sqlite database already has products, seed an empty one")
+		}
+	} else {
+		if c.Schema == "" {
+			c.Schema = fmt.Sprintf("loadertest_%s_%d", strconv.FormatInt(time.Now().UnixNano(), 36), atomic.AddInt64(&schemaCounter, 1))
+		}
+		created, err := writer.CreateSchema(db, c.Schema)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			return nil, fmt.Errorf("schema %s already exists", c.Schema)
+		}
+	}
+
+	batch, err := c.generate(opts)
+	if err == nil {
+		_, err = writer.NewDBSink(db, opts.Dialect).Write(batch...)
+	}
+	if err != nil {
+		if dropErr := c.Drop(); dropErr != nil {
+			return nil, fmt.Errorf("%w (and failed to clean up: %v)", err, dropErr)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// generate runs every generator in order and records the well-known IDs of the catalog. Each phase has
+// its own generator derived from the seed, so changing one phase leaves the others as they were.
+func (c *Catalog) generate(opts Options) ([]writer.Rows, error) {
+	newRand := func(salt int64) *rand.Rand {
+		return rand.New(rand.NewSource(opts.Seed + salt))
+	}
+
+	locales, err := loader.ParseLocaleConfig(loader.DefaultLocales)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := loader.ParseStatusConfig("", loader.DefaultProductStatuses)
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := loader.ParsePricingConfig("", loader.DefaultCharmRate)
+	if err != nil {
+		return nil, err
+	}
+	promo, err := loader.ParsePromoConfig("", loader.DefaultPromoDurations, loader.DefaultPromoLookback, loader.DefaultPromoLookahead, loader.DefaultPromoPaused, false)
+	if err != nil {
+		return nil, err
+	}
+	promo.Reference = opts.Now
+	timing, err := loader.ParseDownloadTimeConfig(loader.DefaultDownloadDays, loader.DefaultDiurnalAmplitude, loader.DefaultWeeklyAmplitude, 0, "", loader.DefaultDownloadTimezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Categories and subcategories
+	categories := loader.GenerateCategories(newRand(1), locales)
+	subcategories, err := loader.NewSubcategoryGenerator(categories, loader.DefaultCategoryDepth, locales, 10001)
+	if err != nil {
+		return nil, err
+	}
+	all := append(categories, subcategories.Generate(newRand(2), c.Plan.Subcategories)...)
+
+	for i, cat := range all {
+		all[i].CreatedAt = opts.Now
+		if cat.ParentID == 0 {
+			c.CategoryIDs = append(c.CategoryIDs, cat.ID)
+		} else {
+			c.SubcategoryIDs = append(c.SubcategoryIDs, cat.ID)
+		}
+	}
+
+	// Tags
+	tagGenerator, err := loader.NewTagGenerator(loader.TagMetadataConfig{
+		CuratedRatio:  loader.DefaultCuratedTagRatio,
+		LandingRatio:  loader.DefaultLandingTagRatio,
+		CategoryRatio: loader.DefaultCategoryTagRatio,
+		PopularityExp: loader.DefaultTagPopularityExp,
+	}, c.Plan.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tags := tagGenerator.Generate(newRand(3), 1, c.Plan.Tags)
+
+	// Products, tagged with the tags numbered from 1
+	catalog := loader.NewProductCatalog(all, int64(len(tags)))
+	productGenerator, err := loader.NewProductGenerator(loader.ProductConfig{
+		Locales:  locales,
+		Statuses: statuses,
+		Pricing:  pricing,
+		Timeline: loader.CatalogAgeConfig{Years: loader.DefaultCatalogYears, Growth: loader.DefaultCatalogGrowth},
+		Authors: loader.AuthorConfig{
+			Count:             c.Plan.Authors,
+			Alpha:             loader.DefaultAuthorAlpha,
+			Seed:              loader.DefaultAuthorSeed,
+			CrossCategoryRate: loader.DefaultCrossCategoryRate,
+		},
+		Payload: loader.PayloadConfig{
+			ImagesMean:    loader.DefaultImagesMean,
+			AssetsMean:    loader.DefaultAssetsMean,
+			MetadataScale: loader.DefaultMetadataScale,
+		},
+	}, catalog, 1, c.Plan.Products, opts.Now)
+	if err != nil {
+		return nil, err
+	}
+	products := productGenerator.Generate(newRand(4), 1, c.Plan.Products)
+
+	// The hot tag goes to Plan.HotTag more products, like the hugetag step of -mode=all
+	c.HotTagProducts, err = loader.AttachHotTag(newRand(5), products, c.HotTagID, c.Plan.HotTag)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]loader.ProductRef, 0, len(products))
+	var published []loader.ProductRef
+	for _, p := range products {
+		c.ProductIDs = append(c.ProductIDs, p.ID)
+		ref := loader.ProductRef{ID: p.ID, CategoryID: p.CategoryID, CreatedAt: p.CreatedAt}
+		refs = append(refs, ref)
+		if p.ProductStatus == "published" {
+			published = append(published, ref)
+		}
+	}
+
+	// Promos go to distinct published products like in the promos mode, none of which has a promo yet.
+	// The first one is pinned to run at Now, so the catalog always has an active promo.
+	rng := newRand(6)
+	targets, err := loader.SampleProducts(rng, published, min(c.Plan.Promos, len(published)), nil)
+	if err != nil {
+		return nil, err
+	}
+	promos := promo.GeneratePromos(rng, 1, targets)
+	if len(promos) == 0 {
+		return nil, fmt.Errorf("no published product to give a promo")
+	}
+	promos[0] = promo.ActivePromo(promos[0].ID, targets[0])
+	c.PromoProductID = promos[0].ProductID
+
+	promoted := make(map[int64]bool)
+	for _, p := range promos {
+		if p.Status == "active" {
+			promoted[p.ProductID] = true
+		}
+	}
+
+	// Downloads
+	rng = newRand(7)
+	downloadGenerator, err := loader.NewDownloadGenerator(timing, loader.DownloadPopularityConfig{
+		Zipf:            loader.DefaultPopularityZipf,
+		RecencyBoost:    loader.DefaultRecencyBoost,
+		RecencyHalfLife: loader.DefaultRecencyHalfLife,
+		PromoBoost:      loader.DefaultPromoBoost,
+		Trending:        loader.DefaultTrendingProducts,
+		TrendingHours:   loader.DefaultTrendingHours,
+		TrendingShare:   loader.DefaultTrendingShare,
+	}, refs, promoted, opts.Now, rng)
+	if err != nil {
+		return nil, err
+	}
+	downloads := downloadGenerator.Generate(rng, 1, c.Plan.Downloads)
+
+	perProduct := make(map[int64]int)
+	for _, d := range downloads {
+		perProduct[d.ProductID]++
+	}
+	for _, id := range c.ProductIDs {
+		if perProduct[id] > c.TopDownloads {
+			c.TopDownloadedProductID, c.TopDownloads = id, perProduct[id]
+		}
+	}
+
+	batch := []writer.Rows{loader.CategoryRows(all), loader.TagRows(tags)}
+	batch = append(batch, loader.ProductRows(products)...)
+	batch = append(batch, loader.PromoRows(promos), loader.DownloadRows(downloads))
+	for i := range batch {
+		batch[i].Table = c.Table(batch[i].Table)
+	}
+	return batch, nil
+}
+
+// Table returns the name of a table qualified with the schema of the catalog, for queries on
+// connections that don't select it
+func (c *Catalog) Table(name string) string {
+	return pq.QuoteIdentifier(c.Schema) + "." + name
+}
+
+// Drop removes the catalog: the whole schema, or on SQLite the seeded rows
+func (c *Catalog) Drop() error {
+	if c.dialect.Driver() != "sqlite" {
+		return writer.DropSchema(c.db, c.Schema)
+	}
+	for i := len(seededTables) - 1; i >= 0; i-- {
+		if _, err := c.db.Exec("DELETE FROM " + c.Table(seededTables[i])); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", seededTables[i], err)
+		}
+	}
+	return nil
+}
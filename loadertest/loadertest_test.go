@@ -0,0 +1,130 @@
+package loadertest
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"tiny-cds-loader/writer"
+)
+
+// testDSNEnv points the tests needing PostgreSQL at a database they may create schemas in
+const testDSNEnv = "TINY_CDS_LOADER_TEST_DSN"
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "seed.db")+"?_time_format=sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// fingerprint summarizes the seeded rows, so two catalogs with different contents tell apart
+func fingerprint(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	var s string
+	err := db.QueryRow(`
+		SELECT (SELECT group_concat(product_id || ':' || price_in_cents || ':' || created_at, ',') FROM product)
+		    || '|' || (SELECT group_concat(product_id || ':' || status || ':' || expires_at, ',') FROM product_promo)
+		    || '|' || (SELECT group_concat(product_id || ':' || downloaded_at, ',') FROM product_download)
+		    || '|' || (SELECT group_concat(category_id || ':' || created_at, ',') FROM category)
+	`).Scan(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSeedIsDeterministic(t *testing.T) {
+	opts := Options{Seed: 7, Now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), Dialect: writer.SQLite{}}
+
+	var first *Catalog
+	var firstRows string
+	for run := 0; run < 2; run++ {
+		db := openSQLite(t)
+		c, err := Seed(db, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows := fingerprint(t, db)
+
+		if run == 0 {
+			first, firstRows = c, rows
+		} else {
+			if c.HotTagID != first.HotTagID || c.PromoProductID != first.PromoProductID || c.TopDownloadedProductID != first.TopDownloadedProductID {
+				t.Errorf("well-known IDs differ: hot tag %d/%d, promo product %d/%d, top downloaded %d/%d",
+					first.HotTagID, c.HotTagID, first.PromoProductID, c.PromoProductID, first.TopDownloadedProductID, c.TopDownloadedProductID)
+			}
+			if rows != firstRows {
+				t.Error("seeded rows differ between two seeds with the same options")
+			}
+		}
+
+		var hot, promo, downloads int
+		db.QueryRow("SELECT COUNT(DISTINCT product_id) FROM product_tag WHERE tag_id = $1", c.HotTagID).Scan(&hot)
+		db.QueryRow("SELECT COUNT(*) FROM product_promo WHERE product_id = $1 AND status = 'active'", c.PromoProductID).Scan(&promo)
+		db.QueryRow("SELECT COUNT(*) FROM product_download WHERE product_id = $1", c.TopDownloadedProductID).Scan(&downloads)
+		if hot != c.HotTagProducts || hot < c.Plan.HotTag || promo != 1 || downloads != c.TopDownloads {
+			t.Errorf("hot tag on %d products (want %d, at least %d), %d active promos (want 1), %d top downloads (want %d)",
+				hot, c.HotTagProducts, c.Plan.HotTag, promo, downloads, c.TopDownloads)
+		}
+
+		if err := c.Drop(); err != nil {
+			t.Fatal(err)
+		}
+		for _, table := range seededTables {
+			var n int
+			if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+				t.Fatal(err)
+			}
+			if n != 0 {
+				t.Errorf("%s has %d rows after Drop", table, n)
+			}
+		}
+	}
+}
+
+func TestNewPostgres(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	c := New(t, db, DefaultProfile)
+	var products int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + c.Table("product")).Scan(&products); err != nil {
+		t.Fatal(err)
+	}
+	if products != c.Plan.Products {
+		t.Errorf("%s has %d products, want %d", c.Schema, products, c.Plan.Products)
+	}
+}
+
+func TestSeedAlwaysHasActivePromo(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		db := openSQLite(t)
+		c, err := Seed(db, Options{Scale: 0.1, Seed: seed, Now: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), Dialect: writer.SQLite{}})
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		var active int
+		if err := db.QueryRow("SELECT COUNT(*) FROM product_promo WHERE product_id = $1 AND status = 'active'", c.PromoProductID).Scan(&active); err != nil {
+			t.Fatal(err)
+		}
+		if active != 1 {
+			t.Errorf("seed %d: product %d has %d active promos, want 1", seed, c.PromoProductID, active)
+		}
+	}
+}
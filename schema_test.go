@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"tiny-cds-loader/loader"
+	"tiny-cds-loader/writer"
+)
+
+// testDSNEnv points the tests needing PostgreSQL at a database they may create schemas in
+const testDSNEnv = "TINY_CDS_LOADER_TEST_DSN"
+
+// TestWorkersWriteToSchema opens the pool the way -schema does and has every worker insert into an
+// unqualified table that exists both in the chosen schema and in public, then use the ltree extension
+// of public like -mode=verify
+func TestWorkersWriteToSchema(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	connStr, err := ConnectionConfig{DSN: dsn}.connString()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := sql.Open("postgres", connStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	schema := fmt.Sprintf("schema_test_%d", os.Getpid())
+	table := schema + "_rows"
+	for _, stmt := range []string{
+		"CREATE EXTENSION IF NOT EXISTS ltree",
+		"CREATE SCHEMA " + pq.QuoteIdentifier(schema),
+		fmt.Sprintf("CREATE TABLE %s.%s (worker int NOT NULL)", pq.QuoteIdentifier(schema), table),
+		fmt.Sprintf("CREATE TABLE public.%s (worker int NOT NULL)", table),
+	} {
+		if _, err := admin.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	defer func() {
+		admin.Exec("DROP SCHEMA " + pq.QuoteIdentifier(schema) + " CASCADE")
+		admin.Exec("DROP TABLE public." + table)
+	}()
+
+	db, err := sql.Open("postgres", connStr+" "+searchPathParam(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(loader.Workers)
+
+	if err := writer.CheckSchema(db, schema, loader.Workers); err != nil {
+		t.Fatalf("schema check failed: %v", err)
+	}
+
+	// Every worker holds its own connection until all of them have one, so no connection is reused
+	ctx := context.Background()
+	var ready, wg sync.WaitGroup
+	ready.Add(loader.Workers)
+	errors := make(chan error, loader.Workers)
+	for w := 0; w < loader.Workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			conn, err := db.Conn(ctx)
+			ready.Done()
+			if err != nil {
+				errors <- err
+				return
+			}
+			defer conn.Close()
+			ready.Wait()
+			if _, err := conn.ExecContext(ctx, "INSERT INTO "+table+" (worker) VALUES ($1)", workerID); err != nil {
+				errors <- fmt.Errorf("worker %d: %w", workerID, err)
+				return
+			}
+			// The ltree functions and operators of -mode=verify live in public
+			var levels int
+			if err := conn.QueryRowContext(ctx, "SELECT nlevel(text2ltree('1.2') || text2ltree($1::text))", workerID).Scan(&levels); err != nil {
+				errors <- fmt.Errorf("worker %d: %w", workerID, err)
+			} else if levels != 3 {
+				errors <- fmt.Errorf("worker %d: got %d ltree levels, want 3", workerID, levels)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errors)
+	for err := range errors {
+		t.Fatal(err)
+	}
+
+	var inSchema, inPublic int
+	if err := admin.QueryRow(fmt.Sprintf("SELECT COUNT(DISTINCT worker) FROM %s.%s", pq.QuoteIdentifier(schema), table)).Scan(&inSchema); err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.QueryRow("SELECT COUNT(*) FROM public." + table).Scan(&inPublic); err != nil {
+		t.Fatal(err)
+	}
+	if inSchema != loader.Workers || inPublic != 0 {
+		t.Fatalf("%d workers wrote to %s and %d rows went to public, want %d and 0", inSchema, schema, inPublic, loader.Workers)
+	}
+}
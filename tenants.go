@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"tiny-cds-loader/loader"
+	"tiny-cds-loader/writer"
+)
+
+const (
+	defaultParallelSchemas = 2
+	tenantSeedStride       = 1000003 // Distance between the seeds of consecutive schemas
+	tenantFailureLines     = 20      // Output lines shown for a schema that failed
+)
+
+// tenantTables are the tables counted in the per-schema summary
+var tenantTables = []string{"category", "tag", "product", "product_tag", "product_promo", "product_download"}
+
+// tenantSpec is one schema of a multi-schema run
+type tenantSpec struct {
+	schema     string
+	multiplier float64 // Applied to -scale
+	seed       int64
+}
+
+// schemaRange matches a numbered range such as "{001..100}"; the width of the start pads the numbers
+var schemaRange = regexp.MustCompile(`\{(\d+)\.\.(\d+)\}`)
+
+// parseSchemaList expands "tenant_{001..010},big_tenant:4" into schemas with their size multiplier and
+// derives each seed from the base seed, so every schema gets its own data and a run can be reproduced
+func parseSchemaList(spec string, seed int64) ([]tenantSpec, error) {
+	var tenants []tenantSpec
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pattern, multiplierSpec, hasMultiplier := strings.Cut(item, ":")
+		multiplier := 1.0
+		if hasMultiplier {
+			var err error
+			multiplier, err = strconv.ParseFloat(strings.TrimSpace(multiplierSpec), 64)
+			if err != nil || multiplier <= 0 {
+				return nil, fmt.Errorf("invalid size multiplier in %q, expected > 0", item)
+			}
+		}
+
+		names := []string{strings.TrimSpace(pattern)}
+		if m := schemaRange.FindStringSubmatchIndex(pattern); m != nil {
+			first, _ := strconv.Atoi(pattern[m[2]:m[3]])
+			last, _ := strconv.Atoi(pattern[m[4]:m[5]])
+			if last < first {
+				return nil, fmt.Errorf("invalid schema range in %q", item)
+			}
+			width := m[3] - m[2]
+			names = names[:0]
+			for i := first; i <= last; i++ {
+				names = append(names, fmt.Sprintf("%s%0*d%s", pattern[:m[0]], width, i, pattern[m[1]:]))
+			}
+		}
+
+		for _, name := range names {
+			if seen[name] {
+				return nil, fmt.Errorf("schema %s is listed twice", name)
+			}
+			seen[name] = true
+			tenants = append(tenants, tenantSpec{
+				schema:     name,
+				multiplier: multiplier,
+				seed:       seed + int64(len(tenants))*tenantSeedStride,
+			})
+		}
+	}
+	if len(tenants) == 0 {
+		return nil, fmt.Errorf("no schemas in %q", spec)
+	}
+	return tenants, nil
+}
+
+// tenantResult is the outcome of loading one schema
+type tenantResult struct {
+	created  bool
+	elapsed  time.Duration
+	err      error
+	output   []byte
+	stats    map[string]int64 // Live rows by table
+	statsErr error
+}
+
+// skippedTenantFlags are set per schema instead of being passed through to the child processes; the
+// connection flags reach them through $TINY_CDS_LOADER_DSN instead
+var skippedTenantFlags = map[string]bool{
+	"schemas": true, "schema": true, "seed": true, "scale": true, "parallel-schemas": true, "create-schema": true, "quiet": true,
+	"db-url": true, "username": true, "password": true, "sslmode": true, "sslrootcert": true, "sslcert": true, "sslkey": true,
+}
+
+// loadSchemas creates the schemas one by one, then runs the requested mode for each schema in a child
+// process of the loader, parallel schemas at a time. Each child gets its own connection pool, seed and
+// scale while everything else, e.g. the author seed or the category weights, comes from the same flags.
+func loadSchemas(db *sql.DB, connStr string, tenants []tenantSpec, parallel int, scale float64, withProfile bool) error {
+	fmt.Printf("\n=== Loading %d Schemas (%d at a time) ===\n\n", len(tenants), parallel)
+
+	if parallel < 1 {
+		return fmt.Errorf("parallel schemas must be >= 1, got %d", parallel)
+	}
+	for _, t := range tenants {
+		if t.multiplier != 1 && !withProfile {
+			return fmt.Errorf("size multiplier of schema %s requires -profile", t.schema)
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the loader executable: %w", err)
+	}
+
+	var shared []string
+	flag.Visit(func(f *flag.Flag) {
+		if !skippedTenantFlags[f.Name] {
+			shared = append(shared, fmt.Sprintf("-%s=%s", f.Name, f.Value.String()))
+		}
+	})
+
+	results := make([]tenantResult, len(tenants))
+	for i, t := range tenants {
+		created, err := writer.CreateSchema(db, t.schema)
+		if err != nil {
+			return err
+		}
+		results[i].created = created
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	slots := make(chan struct{}, parallel)
+	for i, t := range tenants {
+		wg.Add(1)
+		go func(i int, t tenantSpec) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			args := append([]string{}, shared...)
+			args = append(args, "-schema="+t.schema, fmt.Sprintf("-seed=%d", t.seed), "-quiet")
+			if withProfile {
+				args = append(args, fmt.Sprintf("-scale=%g", scale*t.multiplier))
+			}
+
+			var output bytes.Buffer
+			cmd := exec.Command(executable, args...)
+			cmd.Stdout = &output
+			cmd.Stderr = &output
+			cmd.Env = append(os.Environ(), dsnEnv+"="+connStr)
+
+			start := time.Now()
+			err := cmd.Run()
+			r := &results[i]
+			r.elapsed = time.Since(start)
+			r.err = err
+			r.output = output.Bytes()
+
+			mu.Lock()
+			if err != nil {
+				fmt.Printf("  ✗ %s failed after %s\n", t.schema, r.elapsed.Round(time.Second))
+			} else {
+				fmt.Printf("  ✓ %s done in %s\n", t.schema, r.elapsed.Round(time.Second))
+			}
+			mu.Unlock()
+		}(i, t)
+	}
+	wg.Wait()
+
+	for i, t := range tenants {
+		qualified := make([]string, len(tenantTables))
+		for j, table := range tenantTables {
+			qualified[j] = pq.QuoteIdentifier(t.schema) + "." + table
+		}
+		live, err := loader.LiveTuples(db, qualified)
+		results[i].statsErr = err
+		results[i].stats = make(map[string]int64, len(tenantTables))
+		for j, table := range tenantTables {
+			results[i].stats[table] = live[qualified[j]]
+		}
+	}
+
+	return printTenantSummary(tenants, results, scale, withProfile)
+}
+
+// printTenantSummary prints one line per schema and the output of the schemas that failed
+func printTenantSummary(tenants []tenantSpec, results []tenantResult, scale float64, withProfile bool) error {
+	fmt.Println("\n  Per-schema summary (rows are estimates from pg_stat_user_tables):")
+	fmt.Printf("  %-20s %-6s %8s %22s %8s", "Schema", "Status", "Time", "Seed", "Scale")
+	for _, table := range tenantTables {
+		fmt.Printf(" %14s", table)
+	}
+	fmt.Println()
+
+	failed := 0
+	for i, t := range tenants {
+		r := results[i]
+		status := "ok"
+		if r.err != nil {
+			status = "failed"
+			failed++
+		}
+		if r.created {
+			status += "*"
+		}
+		scaleColumn := "-"
+		if withProfile {
+			scaleColumn = fmt.Sprintf("%g", scale*t.multiplier)
+		}
+		fmt.Printf("  %-20s %-6s %8s %22d %8s", t.schema, status, r.elapsed.Round(time.Second), t.seed, scaleColumn)
+		for _, table := range tenantTables {
+			if r.statsErr != nil {
+				fmt.Printf(" %14s", "?")
+				continue
+			}
+			fmt.Printf(" %14d", r.stats[table])
+		}
+		fmt.Println()
+	}
+	fmt.Println("  * schema created by this run")
+
+	for i, t := range tenants {
+		if results[i].err == nil {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(string(results[i].output), "\n"), "\n")
+		if len(lines) > tenantFailureLines {
+			lines = lines[len(lines)-tenantFailureLines:]
+		}
+		fmt.Printf("\n  Last output of %s (%v):\n", t.schema, results[i].err)
+		for _, line := range lines {
+			fmt.Printf("    %s\n", redactSecrets(line))
+		}
+	}
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d schemas failed", failed, len(tenants))
+	}
+	return nil
+}
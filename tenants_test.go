@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSchemaList(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []tenantSpec
+		wantErr bool
+	}{
+		{"tenant_a", []tenantSpec{{"tenant_a", 1, 7}}, false},
+		{"tenant_a, tenant_b:2.5", []tenantSpec{{"tenant_a", 1, 7}, {"tenant_b", 2.5, 7 + tenantSeedStride}}, false},
+		{"tenant_{8..10}", []tenantSpec{
+			{"tenant_8", 1, 7},
+			{"tenant_9", 1, 7 + tenantSeedStride},
+			{"tenant_10", 1, 7 + 2*tenantSeedStride},
+		}, false},
+		{"t_{009..011}_x:4,big", []tenantSpec{
+			{"t_009_x", 4, 7},
+			{"t_010_x", 4, 7 + tenantSeedStride},
+			{"t_011_x", 4, 7 + 2*tenantSeedStride},
+			{"big", 1, 7 + 3*tenantSeedStride},
+		}, false},
+		{"t_{1..1},,", []tenantSpec{{"t_1", 1, 7}}, false},
+		{"t_{3..1}", nil, true},
+		{"a,b,a", nil, true},
+		{"t_{1..3},t_2", nil, true},
+		{"a:0", nil, true},
+		{"a:-1", nil, true},
+		{"a:big", nil, true},
+		{" , ", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSchemaList(tt.spec, 7)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSchemaList(%q): got error %v, want error %t", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSchemaList(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package writer bulk-inserts generated rows into PostgreSQL or SQLite
+package writer
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema_sqlite.sql
+var sqliteSchemaDDL string
+
+const (
+	postgresMaxParams = 65535
+	sqliteMaxParams   = 32766
+)
+
+// Execer is a *sql.DB or a *sql.Tx
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Dialect hides what differs between the databases the loader writes to: the schema DDL, bulk
+// inserts and the discovery of the IDs already taken
+type Dialect interface {
+	// Driver is the database/sql driver name
+	Driver() string
+	// Prepare creates the tables when asked to, or when the dialect always does, and checks that the
+	// connections of db write to schema. It reports whether it created the schema.
+	Prepare(db *sql.DB, schema string, create bool) (bool, error)
+	// InsertRows inserts rows of the given columns in as few statements as the parameter limit allows,
+	// skipping rows that conflict with existing ones, and returns the number of rows inserted
+	InsertRows(db Execer, table string, columns []string, rows [][]interface{}) (int64, error)
+	// MaxID returns the largest value of column in table, 0 when the table is empty
+	MaxID(db *sql.DB, table, column string) (int64, error)
+}
+
+// New returns the dialect of a driver name, "postgres" or "sqlite"; connections is the number of
+// pooled connections the Postgres dialect checks at once
+func New(driver string, connections int) (Dialect, error) {
+	switch driver {
+	case "postgres":
+		return Postgres{Connections: connections}, nil
+	case "sqlite":
+		return SQLite{}, nil
+	}
+	return nil, fmt.Errorf("unknown driver %q, expected postgres or sqlite", driver)
+}
+
+// Postgres writes to the partitioned schema of schema.sql
+type Postgres struct {
+	Connections int // Pooled connections Prepare checks at once, at least one
+}
+
+func (Postgres) Driver() string { return "postgres" }
+
+func (p Postgres) Prepare(db *sql.DB, schema string, create bool) (bool, error) {
+	created := false
+	if create {
+		var err error
+		if created, err = CreateSchema(db, schema); err != nil {
+			return false, err
+		}
+	}
+	// Every pooled connection gets the schema from the connection string, check them all
+	return created, CheckSchema(db, schema, p.Connections)
+}
+
+func (Postgres) InsertRows(db Execer, table string, columns []string, rows [][]interface{}) (int64, error) {
+	return insertRowsWith(db, "INSERT INTO", " ON CONFLICT DO NOTHING", postgresMaxParams, numberedParam, table, columns, rows, func(v interface{}) interface{} { return v })
+}
+
+func (Postgres) MaxID(db *sql.DB, table, column string) (int64, error) {
+	return selectMaxID(db, table, column)
+}
+
+// SQLite writes to a single database file with the simplified schema of schema_sqlite.sql
+type SQLite struct{}
+
+func (SQLite) Driver() string { return "sqlite" }
+
+// Prepare always creates the missing tables, a new database file is the usual starting point.
+// SQLite has no schemas, the only accepted one is the default.
+func (SQLite) Prepare(db *sql.DB, schema string, _ bool) (bool, error) {
+	if schema != "public" && schema != "main" {
+		return false, fmt.Errorf("the sqlite driver has no schemas, got %q", schema)
+	}
+	if _, err := db.Exec(sqliteSchemaDDL); err != nil {
+		return false, fmt.Errorf("failed to create the sqlite tables: %w", err)
+	}
+	return false, nil
+}
+
+// InsertRows stores timestamps in UTC so their text form sorts and compares chronologically. Parameters are
+// positional: the driver looks up every $n parameter by name, which gets slow with thousands per statement.
+func (SQLite) InsertRows(db Execer, table string, columns []string, rows [][]interface{}) (int64, error) {
+	return insertRowsWith(db, "INSERT OR IGNORE INTO", "", sqliteMaxParams, positionalParam, table, columns, rows, func(v interface{}) interface{} {
+		if t, ok := v.(time.Time); ok {
+			return t.UTC()
+		}
+		return v
+	})
+}
+
+func (SQLite) MaxID(db *sql.DB, table, column string) (int64, error) {
+	return selectMaxID(db, table, column)
+}
+
+// numberedParam and positionalParam write the placeholder of the n-th parameter of a statement
+func numberedParam(query *strings.Builder, n int)   { fmt.Fprintf(query, "$%d", n) }
+func positionalParam(query *strings.Builder, _ int) { query.WriteString("?") }
+
+// insertRowsWith builds multi-row INSERT statements with the placeholders of param
+func insertRowsWith(db Execer, insert, suffix string, maxParams int, param func(*strings.Builder, int), table string, columns []string, rows [][]interface{}, convert func(interface{}) interface{}) (int64, error) {
+	perStatement := maxParams / len(columns)
+	prefix := fmt.Sprintf("%s %s (%s) VALUES ", insert, table, strings.Join(columns, ", "))
+
+	var inserted int64
+	for start := 0; start < len(rows); start += perStatement {
+		end := start + perStatement
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var query strings.Builder
+		query.WriteString(prefix)
+		args := make([]interface{}, 0, (end-start)*len(columns))
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString("(")
+			for j, v := range row {
+				if j > 0 {
+					query.WriteString(", ")
+				}
+				args = append(args, convert(v))
+				param(&query, len(args))
+			}
+			query.WriteString(")")
+		}
+		query.WriteString(suffix)
+
+		result, err := db.Exec(query.String(), args...)
+		if err != nil {
+			return inserted, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += n
+	}
+	return inserted, nil
+}
+
+// selectMaxID is the portable ID discovery of both dialects
+func selectMaxID(db *sql.DB, table, column string) (int64, error) {
+	var id int64
+	err := db.QueryRow(fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) FROM %s", column, table)).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,89 @@
+package writer
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		driver  string
+		wantErr bool
+	}{
+		{"postgres", false},
+		{"sqlite", false},
+		{"mysql", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		d, err := New(tt.driver, 4)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q): got error %v, want error %t", tt.driver, err, tt.wantErr)
+			continue
+		}
+		if err == nil && d.Driver() != tt.driver {
+			t.Errorf("New(%q).Driver() = %q", tt.driver, d.Driver())
+		}
+		if p, ok := d.(Postgres); ok && p.Connections != 4 {
+			t.Errorf("New(%q) checks %d connections, want 4", tt.driver, p.Connections)
+		}
+	}
+}
+
+func TestSQLiteInsertRows(t *testing.T) {
+	db, err := sql.Open("sqlite", "file::memory:?_time_format=sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE item (item_id INTEGER PRIMARY KEY, created_at TIMESTAMP NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, paris)
+	rows := make([][]interface{}, 5)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i + 1), createdAt}
+	}
+
+	// 4 parameters per statement split the 5 rows of 2 columns into 3 statements
+	inserted, err := insertRowsWith(db, "INSERT OR IGNORE INTO", "", 4, numberedParam, "item", []string{"item_id", "created_at"}, rows, func(v interface{}) interface{} { return v })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted != 5 {
+		t.Errorf("inserted %d rows, want 5", inserted)
+	}
+
+	// Conflicting rows are skipped and not counted, timestamps are stored in UTC
+	d := SQLite{}
+	inserted, err = d.InsertRows(db, "item", []string{"item_id", "created_at"}, append(rows[3:], []interface{}{int64(6), createdAt}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inserted != 1 {
+		t.Errorf("inserted %d rows with 2 conflicts, want 1", inserted)
+	}
+
+	maxID, err := d.MaxID(db, "item", "item_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxID != 6 {
+		t.Errorf("maxID = %d, want 6", maxID)
+	}
+
+	var stored string
+	if err := db.QueryRow("SELECT CAST(created_at AS TEXT) FROM item WHERE item_id = 6").Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if want := "2026-03-01 11:00:00"; len(stored) < len(want) || stored[:len(want)] != want {
+		t.Errorf("created_at stored as %q, want UTC %s", stored, want)
+	}
+}
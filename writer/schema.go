@@ -0,0 +1,145 @@
+package writer
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+//go:embed schema.sql
+var schemaDDL string
+
+// createdSchemaName restricts the schemas the loader creates to names schema.sql can be rewritten for,
+// pg_partman takes the parent table as an unquoted "schema.table" string
+var createdSchemaName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// CheckSchema holds connections at once and checks that each of them resolves unqualified table
+// names to schema, so no worker silently writes to another schema
+func CheckSchema(db *sql.DB, schema string, connections int) error {
+	if connections < 1 {
+		connections = 1
+	}
+	ctx := context.Background()
+
+	// public follows the schema in the search_path, so a missing schema would silently fall back to it
+	var exists bool
+	if err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)", schema).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up schema %s: %w", schema, err)
+	}
+	if !exists {
+		return fmt.Errorf("schema %q does not exist", schema)
+	}
+
+	conns := make([]*sql.Conn, 0, connections)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	for i := 0; i < connections; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to open connection %d: %w", i+1, err)
+		}
+		conns = append(conns, conn)
+
+		// current_schema() is NULL when no schema of the search_path exists
+		var current sql.NullString
+		if err := conn.QueryRowContext(ctx, "SELECT current_schema()").Scan(&current); err != nil {
+			return fmt.Errorf("failed to read the schema of connection %d: %w", i+1, err)
+		}
+		if !current.Valid {
+			return fmt.Errorf("schema %q does not exist", schema)
+		}
+		if current.String != schema {
+			return fmt.Errorf("connection %d uses schema %q instead of %q", i+1, current.String, schema)
+		}
+	}
+	return nil
+}
+
+// CreateSchema creates the schema and its tables from schema.sql unless the product table already exists
+func CreateSchema(db *sql.DB, schema string) (bool, error) {
+	if !createdSchemaName.MatchString(schema) {
+		return false, fmt.Errorf("cannot create schema %q, expected a lower-case identifier such as tenant_001", schema)
+	}
+
+	var exists bool
+	err := db.QueryRow("SELECT to_regclass($1) IS NOT NULL", pq.QuoteIdentifier(schema)+".product").Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up schema %s: %w", schema, err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	// schema.sql targets public; its unqualified names resolve through the search_path of the connection
+	ddl := strings.ReplaceAll(schemaDDL, "public.ltree", "ltree_type_placeholder")
+	ddl = strings.ReplaceAll(ddl, "public.", schema+".")
+	ddl = strings.ReplaceAll(ddl, "partman.product_template", "partman."+schema+"_product_template")
+	// The template lives in the shared partman schema, so its constraint names must be unique per schema too
+	ddl = strings.ReplaceAll(ddl, "CONSTRAINT product_category_id_unique", "CONSTRAINT "+schema+"_product_category_id_unique")
+	ddl = strings.ReplaceAll(ddl, "ltree_type_placeholder", "public.ltree")
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+pq.QuoteIdentifier(schema)); err != nil {
+		return false, fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+	if _, err := conn.ExecContext(ctx, "SET search_path TO "+pq.QuoteIdentifier(schema)+", public"); err != nil {
+		return false, fmt.Errorf("failed to select schema %s: %w", schema, err)
+	}
+	// Reset before the connection returns to the pool, whatever happens to the DDL
+	defer conn.ExecContext(ctx, "RESET search_path")
+
+	if _, err := conn.ExecContext(ctx, ddl); err != nil {
+		return false, fmt.Errorf("failed to create the tables of schema %s: %w", schema, err)
+	}
+	return true, nil
+}
+
+// DropSchema drops a schema created by CreateSchema with all of its tables, along with its pg_partman
+// template table and configuration
+func DropSchema(db *sql.DB, schema string) error {
+	if !createdSchemaName.MatchString(schema) {
+		return fmt.Errorf("cannot drop schema %q, expected a lower-case identifier such as tenant_001", schema)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var partman bool
+	if err := tx.QueryRow("SELECT to_regclass('partman.part_config') IS NOT NULL").Scan(&partman); err != nil {
+		return fmt.Errorf("failed to look up pg_partman: %w", err)
+	}
+	if partman {
+		if _, err := tx.Exec("DELETE FROM partman.part_config WHERE parent_table = $1", schema+".product"); err != nil {
+			return fmt.Errorf("failed to remove the partitioning of schema %s: %w", schema, err)
+		}
+		if _, err := tx.Exec("DROP TABLE IF EXISTS partman." + pq.QuoteIdentifier(schema+"_product_template")); err != nil {
+			return fmt.Errorf("failed to drop the partition template of schema %s: %w", schema, err)
+		}
+	}
+	if _, err := tx.Exec("DROP SCHEMA IF EXISTS " + pq.QuoteIdentifier(schema) + " CASCADE"); err != nil {
+		return fmt.Errorf("failed to drop schema %s: %w", schema, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
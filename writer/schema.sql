@@ -16,6 +16,7 @@ CREATE TABLE product_tag
 (
     product_id         int8 NOT NULL,
     tag_id             int8 NOT NULL,
+    product_created_at timestamptz NULL, -- created_at of the product, for the newest products of a tag
     CONSTRAINT product_tag_pk PRIMARY KEY (product_id, tag_id)
 ) PARTITION BY HASH (tag_id);
 
@@ -0,0 +1,105 @@
+-- Simplified schema for the sqlite dialect: same tables and columns as schema.sql without partitions,
+-- ltree or materialized views. jsonb and ltree columns hold their text form.
+
+CREATE TABLE IF NOT EXISTS category
+(
+    category_id              INTEGER NOT NULL PRIMARY KEY,
+    parent_category_id       INTEGER NULL REFERENCES category (category_id) ON DELETE SET NULL,
+    default_name             TEXT    NOT NULL,
+    default_description      TEXT    NOT NULL,
+    created_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP NULL,
+    updated_at               TIMESTAMP DEFAULT CURRENT_TIMESTAMP NULL,
+    "attributes"             TEXT NULL,
+    url_path                 TEXT    NOT NULL,
+    hierarchy_path           TEXT NULL,
+    name_translations        TEXT NULL,
+    description_translations TEXT NULL,
+    migration_status_id      TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tag
+(
+    tag_id          INTEGER NOT NULL PRIMARY KEY,
+    slug            TEXT    NOT NULL,
+    in_landing_page BOOLEAN DEFAULT false NOT NULL,
+    category        BOOLEAN DEFAULT false NOT NULL,
+    curated         BOOLEAN DEFAULT false NOT NULL,
+    page_content    TEXT NULL
+);
+CREATE INDEX IF NOT EXISTS tag_slug_idx ON tag (slug);
+
+CREATE TABLE IF NOT EXISTS product
+(
+    product_id      INTEGER NOT NULL,
+    author_id       INTEGER NOT NULL,
+    category_id     INTEGER NULL REFERENCES category (category_id) ON DELETE SET NULL,
+    price_in_cents  INTEGER NOT NULL,
+    title           TEXT    NOT NULL,
+    slug            TEXT    NOT NULL,
+    description     TEXT    NOT NULL,
+    main_image      TEXT NULL,
+    images          TEXT NULL,
+    assets          TEXT    NOT NULL,
+    product_type    TEXT    NOT NULL,
+    product_status  TEXT    NOT NULL,
+    metadata        TEXT    NOT NULL,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_updated_at TIMESTAMP NULL,
+    status          TEXT NULL,
+    PRIMARY KEY (product_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS product_category_id_idx ON product (category_id);
+CREATE INDEX IF NOT EXISTS product_status_idx ON product (status);
+
+CREATE TABLE IF NOT EXISTS product_tag
+(
+    product_id         INTEGER NOT NULL,
+    tag_id             INTEGER NOT NULL,
+    product_created_at TIMESTAMP NULL,
+    PRIMARY KEY (product_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS product_tag_tag_id_product_id_desc_idx ON product_tag (tag_id, product_id DESC);
+
+CREATE TABLE IF NOT EXISTS product_product_category
+(
+    product_id  INTEGER NOT NULL,
+    category_id INTEGER NOT NULL REFERENCES category (category_id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS product_product_category_category_id_product_id_idx ON product_product_category (category_id, product_id);
+
+CREATE TABLE IF NOT EXISTS product_promo
+(
+    product_promo_id INTEGER   NOT NULL,
+    product_id       INTEGER   NOT NULL PRIMARY KEY,
+    promo_type       TEXT NULL,
+    status           TEXT      NOT NULL,
+    expires_at       TIMESTAMP NOT NULL,
+    created_at       TIMESTAMP NOT NULL,
+    last_updated_at  TIMESTAMP NULL
+);
+CREATE INDEX IF NOT EXISTS product_promo_status_idx ON product_promo (status);
+
+CREATE TABLE IF NOT EXISTS product_download
+(
+    download_id                  INTEGER   NOT NULL PRIMARY KEY,
+    product_id                   INTEGER   NOT NULL,
+    downloaded_at                TIMESTAMP NOT NULL,
+    downloaded_at_day_normalized INTEGER NULL
+);
+CREATE INDEX IF NOT EXISTS product_download_product_id_idx ON product_download (product_id);
+CREATE INDEX IF NOT EXISTS product_download_downloaded_at_idx ON product_download (downloaded_at);
+
+CREATE TABLE IF NOT EXISTS tag_relation
+(
+    tag_id         INTEGER NOT NULL REFERENCES tag (tag_id),
+    related_tag_id INTEGER NOT NULL REFERENCES tag (tag_id),
+    PRIMARY KEY (tag_id, related_tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS bundle_products
+(
+    product_bundle_id INTEGER NOT NULL,
+    product_id        INTEGER NOT NULL,
+    UNIQUE (product_bundle_id, product_id)
+);
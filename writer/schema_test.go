@@ -0,0 +1,68 @@
+package writer
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+// testDSNEnv points the tests needing PostgreSQL at a database they may create schemas in
+const testDSNEnv = "TINY_CDS_LOADER_TEST_DSN"
+
+// TestCreateSchemaTwice creates two schemas in a row, the way -schemas sets up several tenants, so the
+// objects they put in the shared partman schema must not collide
+func TestCreateSchemaTwice(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 1; i <= 2; i++ {
+		schema := fmt.Sprintf("writer_test_%d_%d", os.Getpid(), i)
+		created, err := CreateSchema(db, schema)
+		if err != nil {
+			t.Fatalf("schema %d: %v", i, err)
+		}
+		defer func() {
+			if err := DropSchema(db, schema); err != nil {
+				t.Errorf("failed to drop %s: %v", schema, err)
+			}
+		}()
+		if !created {
+			t.Fatalf("schema %s already existed", schema)
+		}
+
+		var products int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + schema + ".product").Scan(&products); err != nil {
+			t.Fatalf("schema %s: %v", schema, err)
+		}
+	}
+}
+
+func TestCreatedSchemaName(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   bool
+	}{
+		{"tenant_001", true},
+		{"_staging", true},
+		{"Tenant", false},
+		{"1tenant", false},
+		{"tenant-1", false},
+		{"tenant.product", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := createdSchemaName.MatchString(tt.schema); got != tt.want {
+			t.Errorf("createdSchemaName.MatchString(%q) = %t, want %t", tt.schema, got, tt.want)
+		}
+	}
+}
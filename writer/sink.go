@@ -0,0 +1,56 @@
+package writer
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Rows are generated rows of one table, each in the order of Columns
+type Rows struct {
+	Table   string
+	Columns []string
+	Values  [][]interface{}
+}
+
+// Sink receives the rows of the generators batch by batch
+type Sink interface {
+	// Write stores the rows of a batch together, table by table in order, and returns the number of rows
+	// inserted for each of them; rows conflicting with existing ones are skipped
+	Write(batch ...Rows) ([]int64, error)
+}
+
+// DBSink writes every batch in its own transaction, so a batch is either fully written or not at all
+type DBSink struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewDBSink returns a sink writing to db with the SQL of dialect
+func NewDBSink(db *sql.DB, dialect Dialect) *DBSink {
+	return &DBSink{db: db, dialect: dialect}
+}
+
+func (s *DBSink) Write(batch ...Rows) ([]int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inserted := make([]int64, len(batch))
+	for i, rows := range batch {
+		if len(rows.Values) == 0 {
+			continue
+		}
+		n, err := s.dialect.InsertRows(tx, rows.Table, rows.Columns, rows.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert into %s: %w", rows.Table, err)
+		}
+		inserted[i] = n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return inserted, nil
+}
@@ -0,0 +1,62 @@
+package writer
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestDBSinkWrite(t *testing.T) {
+	db, err := sql.Open("sqlite", "file::memory:?_time_format=sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	for _, ddl := range []string{
+		"CREATE TABLE parent (parent_id INTEGER PRIMARY KEY)",
+		"CREATE TABLE child (child_id INTEGER PRIMARY KEY, parent_id INTEGER NOT NULL)",
+	} {
+		if _, err := db.Exec(ddl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sink := NewDBSink(db, SQLite{})
+	inserted, err := sink.Write(
+		Rows{Table: "parent", Columns: []string{"parent_id"}, Values: [][]interface{}{{1}, {2}}},
+		Rows{Table: "child", Columns: []string{"child_id", "parent_id"}},
+		Rows{Table: "child", Columns: []string{"child_id", "parent_id"}, Values: [][]interface{}{{10, 1}, {11, 2}, {12, 2}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2, 0, 3}; !reflect.DeepEqual(inserted, want) {
+		t.Errorf("Write inserted %v rows, want %v", inserted, want)
+	}
+
+	// Conflicting rows are skipped and not counted
+	inserted, err = sink.Write(Rows{Table: "parent", Columns: []string{"parent_id"}, Values: [][]interface{}{{2}, {3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1}; !reflect.DeepEqual(inserted, want) {
+		t.Errorf("Write inserted %v rows with 1 conflict, want %v", inserted, want)
+	}
+
+	// A failing table rolls back the whole batch
+	_, err = sink.Write(
+		Rows{Table: "parent", Columns: []string{"parent_id"}, Values: [][]interface{}{{4}}},
+		Rows{Table: "missing", Columns: []string{"id"}, Values: [][]interface{}{{1}}},
+	)
+	if err == nil {
+		t.Fatal("Write into a missing table succeeded")
+	}
+	var parents int
+	if err := db.QueryRow("SELECT COUNT(*) FROM parent").Scan(&parents); err != nil {
+		t.Fatal(err)
+	}
+	if parents != 3 {
+		t.Errorf("%d parents after the failed batch, want 3", parents)
+	}
+}